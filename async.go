@@ -0,0 +1,197 @@
+package holmes
+
+import (
+	"runtime"
+	"sync"
+)
+
+// OrderingMode controls the ordering of records written asynchronously.
+type OrderingMode int
+
+const (
+	// Strict writes records with a single goroutine, in the order they are logged.
+	Strict OrderingMode = iota
+	// Relaxed formats and writes records with one goroutine per CPU, records
+	// logged closely together may be written out of order.
+	Relaxed
+)
+
+// OverflowPolicy controls what happens to records logged while the Async
+// buffer is full.
+type OverflowPolicy int
+
+const (
+	// Block makes callers wait for room in the buffer, no record is lost but
+	// a log call takes as long as the slowest sink needs to make room.
+	Block OverflowPolicy = iota
+	// Drop discards the records logged while the buffer is full.
+	Drop
+	// DropOldest discards the oldest buffered record to make room.
+	DropOldest
+)
+
+// Async returns a function to make log write records in the background,
+// buffering up to size records. What happens while the buffer is full
+// depends on the Overflow policy.
+func Async(size int) func(Logger) Logger {
+	return func(l Logger) Logger {
+		l.asyncSize = size
+		return l
+	}
+}
+
+// Ordering returns a function to set the ordering of records written by Async.
+// Strict, the default, keeps records in order at the cost of throughput,
+// Relaxed trades the ordering for formatting in parallel.
+func Ordering(mode OrderingMode) func(Logger) Logger {
+	return func(l Logger) Logger {
+		l.ordering = mode
+		return l
+	}
+}
+
+// Overflow returns a function to set the policy applied while the Async buffer
+// is full. Block, the default, never loses records but ties the latency of log
+// calls to the sinks once the buffer fills up, Drop and DropOldest keep log
+// calls fast and count the dropped records, see DropWarningInterval.
+func Overflow(policy OverflowPolicy) func(Logger) Logger {
+	return func(l Logger) Logger {
+		l.overflow = policy
+		return l
+	}
+}
+
+// asyncEntry is a record waiting to be written to its sinks.
+type asyncEntry struct {
+	r     *Record
+	sinks []sink
+}
+
+// asyncWriter writes records to sinks with background drain goroutines.
+type asyncWriter struct {
+	entries chan asyncEntry
+	write   func(r *Record, sinks []sink) (bool, int)
+	counts  *levelCounts
+	dropped func() // counts a delivery dropped by a Channel sink, set by Start
+	drains  sync.WaitGroup
+
+	closeMu sync.RWMutex
+	closed  bool
+
+	mu      sync.Mutex
+	idle    *sync.Cond
+	pending int
+}
+
+func newAsyncWriter(size int, mode OrderingMode, write func(r *Record, sinks []sink) (bool, int), counts *levelCounts) *asyncWriter {
+	aw := &asyncWriter{
+		entries: make(chan asyncEntry, size),
+		write:   write,
+		counts:  counts,
+	}
+	aw.idle = sync.NewCond(&aw.mu)
+	drains := 1
+	if mode == Relaxed {
+		drains = runtime.NumCPU()
+	}
+	aw.drains.Add(drains)
+	for i := 0; i < drains; i++ {
+		go aw.drain()
+	}
+	return aw
+}
+
+// push queues r according to policy. It reports whether r was queued and the
+// number of older records evicted to make room for it, an evicted drop warning
+// counting for the drops it reported. The evicted records are uncounted from
+// the level counts.
+func (aw *asyncWriter) push(r *Record, sinks []sink, policy OverflowPolicy) (queued bool, evicted int) {
+	aw.closeMu.RLock()
+	defer aw.closeMu.RUnlock()
+	if aw.closed {
+		return false, 0
+	}
+	aw.mu.Lock()
+	aw.pending++
+	aw.mu.Unlock()
+	entry := asyncEntry{r: r, sinks: sinks}
+	switch policy {
+	case Drop:
+		select {
+		case aw.entries <- entry:
+			return true, 0
+		default:
+			aw.done()
+			return false, 0
+		}
+	case DropOldest:
+		for {
+			select {
+			case aw.entries <- entry:
+				return true, evicted
+			default:
+			}
+			select {
+			case old := <-aw.entries:
+				aw.uncount(old.r)
+				if old.r.drops > 0 {
+					evicted += int(old.r.drops)
+				} else if !old.r.traceOnly {
+					evicted++
+				}
+				aw.done()
+			default:
+			}
+		}
+	}
+	aw.entries <- entry
+	return true, 0
+}
+
+func (aw *asyncWriter) drain() {
+	defer aw.drains.Done()
+	for entry := range aw.entries {
+		written, dropped := aw.write(entry.r, entry.sinks)
+		if !written {
+			aw.uncount(entry.r)
+		}
+		for ; dropped > 0; dropped-- {
+			aw.dropped()
+		}
+		aw.done()
+	}
+}
+
+// uncount removes r, counted when queued, from the level counts.
+func (aw *asyncWriter) uncount(r *Record) {
+	if !r.traceOnly {
+		aw.counts.dec(r.Level)
+	}
+}
+
+func (aw *asyncWriter) done() {
+	aw.mu.Lock()
+	aw.pending--
+	if aw.pending == 0 {
+		aw.idle.Broadcast()
+	}
+	aw.mu.Unlock()
+}
+
+// flush waits until all pushed records are written.
+func (aw *asyncWriter) flush() {
+	aw.mu.Lock()
+	for aw.pending > 0 {
+		aw.idle.Wait()
+	}
+	aw.mu.Unlock()
+}
+
+// close writes the buffered records and stops the drain goroutines.
+func (aw *asyncWriter) close() {
+	aw.closeMu.Lock()
+	aw.closed = true
+	close(aw.entries)
+	aw.closeMu.Unlock()
+	aw.drains.Wait()
+}
@@ -0,0 +1,185 @@
+package holmes
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAsyncStrictOrder(t *testing.T) {
+	buf := &Buffer{}
+	l := Start(Async(16), Ordering(Strict), AlsoWriter(buf))
+	wg := sync.WaitGroup{}
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			for i := 0; i < 1000; i++ {
+				Infof("%d %d", g, i)
+			}
+			wg.Done()
+		}(g)
+	}
+	wg.Wait()
+	for i := 0; i < 1000; i++ {
+		Infof("%d %d", 8, i)
+	}
+	l.Stop()
+
+	next := make([]int, 9)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 9000 {
+		t.Fatalf("got %d records, want 9000", len(lines))
+	}
+	for _, line := range lines {
+		var g, i int
+		if _, err := fmt.Sscanf(line[strings.LastIndex(line, "- ")+2:], "%d %d", &g, &i); err != nil {
+			t.Fatalf("unexpected record %q", line)
+		}
+		if i != next[g] {
+			t.Fatalf("goroutine %d: got record %d, want %d", g, i, next[g])
+		}
+		next[g]++
+	}
+}
+
+func TestAsyncRelaxed(t *testing.T) {
+	buf := &Buffer{}
+	l := Start(Async(16), Ordering(Relaxed), AlsoWriter(buf))
+	for i := 0; i < 1000; i++ {
+		Infof("%d", i)
+	}
+	l.Stop()
+
+	if n := strings.Count(buf.String(), "\n"); n != 1000 {
+		t.Errorf("got %d records, want 1000", n)
+	}
+}
+
+// gatedWriter blocks every write until its gate is closed, signaling the
+// first write on entered.
+type gatedWriter struct {
+	buf     Buffer
+	gate    chan struct{}
+	entered chan struct{}
+	once    sync.Once
+}
+
+func newGatedWriter() *gatedWriter {
+	return &gatedWriter{gate: make(chan struct{}), entered: make(chan struct{})}
+}
+
+func (w *gatedWriter) Write(p []byte) (int, error) {
+	w.once.Do(func() { close(w.entered) })
+	<-w.gate
+	return w.buf.Write(p)
+}
+
+// saturate logs the first record and waits until it is being written, so the
+// next size records fill the buffer of Async(size).
+func saturate(w *gatedWriter) {
+	Infof("%d", 0)
+	<-w.entered
+}
+
+// checkRecords checks that output holds one record ending with each of wants.
+func checkRecords(t *testing.T, output string, wants []string) {
+	t.Helper()
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != len(wants) {
+		t.Errorf("got %d records %q, want %d", len(lines), lines, len(wants))
+		return
+	}
+	for i, want := range wants {
+		if !strings.HasSuffix(lines[i], want) {
+			t.Errorf("got record %q, want suffix %q", lines[i], want)
+		}
+	}
+}
+
+func TestOverflow(t *testing.T) {
+	tests := []struct {
+		policy OverflowPolicy
+		want   []string
+	}{
+		{Drop, []string{"- 0", "- 1", "- 2", "WARN dropped 7 records dropped=7"}},
+		{DropOldest, []string{"- 0", "- 8", "- 9", "WARN dropped 7 records dropped=7"}},
+	}
+	for _, test := range tests {
+		w := newGatedWriter()
+		l := Start(Async(2), Overflow(test.policy), AlsoWriter(w), DropWarningInterval(time.Hour))
+		saturate(w)
+		for i := 1; i < 10; i++ {
+			Infof("%d", i)
+		}
+		close(w.gate)
+		l.Stop()
+
+		checkRecords(t, w.buf.String(), test.want)
+	}
+}
+
+func TestOverflowLevelCounts(t *testing.T) {
+	for _, policy := range []OverflowPolicy{Drop, DropOldest} {
+		w := newGatedWriter()
+		l := Start(Async(2), Overflow(policy), AlsoWriter(w), FileLevel(FATAL))
+		saturate(w)
+		for i := 1; i < 10; i++ {
+			Infof("%d", i)
+		}
+		close(w.gate)
+		l.async.flush()
+		counts := LevelCounts()
+		l.Stop()
+
+		if counts[INFO] != 3 {
+			t.Errorf("policy %d: got %d info records counted, want the 3 written", policy, counts[INFO])
+		}
+	}
+}
+
+func TestOverflowBlock(t *testing.T) {
+	w := newGatedWriter()
+	l := Start(Async(2), Overflow(Block), AlsoWriter(w))
+	saturate(w)
+	logged := make(chan struct{})
+	go func() {
+		for i := 1; i < 10; i++ {
+			Infof("%d", i)
+		}
+		close(logged)
+	}()
+	select {
+	case <-logged:
+		t.Fatal("log calls did not block on a full buffer")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(w.gate)
+	<-logged
+	l.Stop()
+
+	var wants []string
+	for i := 0; i < 10; i++ {
+		wants = append(wants, fmt.Sprintf("- %d", i))
+	}
+	checkRecords(t, w.buf.String(), wants)
+}
+
+func benchmarkAsync(b *testing.B, mode OrderingMode) {
+	defer Start(LogFilePath(b.TempDir()), Async(1024), Ordering(mode)).Stop()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			Infof("%s", "Wake up, Neo")
+		}
+	})
+}
+
+func BenchmarkAsyncStrict(b *testing.B) {
+	benchmarkAsync(b, Strict)
+}
+
+func BenchmarkAsyncRelaxed(b *testing.B) {
+	benchmarkAsync(b, Relaxed)
+}
@@ -0,0 +1,44 @@
+package holmes
+
+// Channel returns a function to make log also deliver every record to ch,
+// e.g. to process or forward records in custom exporters. While ch is full,
+// Block waits for room and Drop and DropOldest drop the new record, as records
+// cannot be taken back from ch, counting it in the drop warnings. See Overflow.
+// Records matching AlwaysEmit wait for room whatever the policy. Records are
+// delivered to ch once written to the other outputs, which a full ch does not
+// hold up, but with Block the consumers of ch must not wait on the logger,
+// e.g. log synchronously while handling a record, as ch may be full.
+func Channel(ch chan<- Record) func(Logger) Logger {
+	return func(l Logger) Logger {
+		cw := &channelWriter{ch: ch}
+		l.channels = append(l.channels, cw)
+		l.writers = append(l.writers, sink{w: cw, level: DEBUG})
+		return l
+	}
+}
+
+// channelWriter delivers records to a channel.
+type channelWriter struct {
+	ch     chan<- Record
+	policy OverflowPolicy
+}
+
+// Write implements io.Writer, records are delivered by WriteRecord only.
+func (cw *channelWriter) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+// WriteRecord implements RecordWriter, returning errDropped if ch is full.
+// Records of AlwaysEmit wait for room in ch.
+func (cw *channelWriter) WriteRecord(r *Record, line []byte) error {
+	if cw.policy == Block || r.always {
+		cw.ch <- *r
+		return nil
+	}
+	select {
+	case cw.ch <- *r:
+		return nil
+	default:
+		return errDropped
+	}
+}
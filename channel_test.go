@@ -0,0 +1,118 @@
+package holmes
+
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChannel(t *testing.T) {
+	ch := make(chan Record, 1)
+	l := Start(Channel(ch), BaseFields("user", "neo"))
+	_, file, line, _ := runtime.Caller(0)
+	Warnf("%s", "knock knock")
+	l.Stop()
+
+	r := <-ch
+	if r.Level != WARN || r.Message != "knock knock" || r.Time.IsZero() {
+		t.Errorf("got level %s, message %q and time %s", r.Level, r.Message, r.Time)
+	}
+	if filepath.Base(r.Func) != "holmes.TestChannel" || r.File != file || r.Line != line+1 {
+		t.Errorf("got caller %s (%s:%d), want %s:%d", r.Func, r.File, r.Line, file, line+1)
+	}
+	if len(r.Fields) != 1 || r.Fields[0] != (Field{Key: "user", Value: "neo"}) {
+		t.Errorf("got fields %v", r.Fields)
+	}
+}
+
+func TestChannelDrop(t *testing.T) {
+	ch := make(chan Record, 1)
+	buf := &Buffer{}
+	l := Start(Channel(ch), Overflow(Drop), AlsoWriter(buf))
+	Infof("%s", "first")
+	Infof("%s", "second")
+	Infof("%s", "third")
+	l.Stop()
+
+	if len(ch) != 1 {
+		t.Fatalf("got %d records on the channel, want 1", len(ch))
+	}
+	if r := <-ch; r.Message != "first" {
+		t.Errorf("got record %q, want the first one", r.Message)
+	}
+	// the first drop is reported right away, the second one on Stop
+	if n := strings.Count(buf.String(), "dropped 1 records"); n != 2 {
+		t.Errorf("got %q, want the drops reported twice", buf.String())
+	}
+}
+
+func TestChannelDropLevelCounts(t *testing.T) {
+	ch := make(chan Record, 1)
+	l := Start(Channel(ch), Overflow(Drop), FileLevel(FATAL))
+	Infof("%s", "first")
+	Infof("%s", "second")
+	Infof("%s", "third")
+	counts := LevelCounts()
+	l.Stop()
+
+	if counts[INFO] != 1 {
+		t.Errorf("got %d info records counted, want the one delivered", counts[INFO])
+	}
+}
+
+func TestChannelDropWarningInterval(t *testing.T) {
+	clk := newTestClock(time.Date(2016, 7, 8, 11, 25, 0, 0, time.Local))
+	ch := make(chan Record, 1)
+	buf := &Buffer{}
+	l := Start(WithClock(clk), Channel(ch), Overflow(Drop), DropWarningInterval(time.Second), AlsoWriter(buf))
+	for i := 0; i < 5; i++ {
+		Infof("record %d", i)
+		clk.add(2 * time.Second)
+	}
+	out := buf.String()
+	l.Stop()
+
+	// every record after the first one is dropped and reported right away
+	if n := strings.Count(out, "dropped 1 records"); n != 4 {
+		t.Errorf("got %d drop warnings before Stop, want 4: %q", n, out)
+	}
+}
+
+func TestChannelFullUnlocked(t *testing.T) {
+	ch := make(chan Record, 1)
+	buf := &Buffer{}
+	l := Start(Channel(ch), AlsoWriter(buf))
+	defer l.Stop()
+	Infof("%s", "fills the channel")
+	blocked := make(chan struct{})
+	go func() {
+		defer close(blocked)
+		Infof("%s", "waits for room") // with Block
+	}()
+
+	// the other outputs are not held up by the full channel
+	logged := make(chan struct{})
+	go func() {
+		defer close(logged)
+		Infof("%s", "meanwhile")
+	}()
+	deadline := time.After(5 * time.Second)
+	for !strings.Contains(buf.String(), "meanwhile") {
+		select {
+		case <-deadline:
+			t.Fatal("a record waited for room in the channel")
+		case <-time.After(time.Millisecond):
+		}
+	}
+	for i := 0; i < 3; i++ {
+		select {
+		case <-ch:
+		case <-deadline:
+			t.Fatal("records not delivered to the channel")
+		}
+	}
+	<-blocked
+	<-logged
+}
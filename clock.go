@@ -0,0 +1,38 @@
+package holmes
+
+import "time"
+
+// Clock is the source of time of holmes, it stamps records and triggers
+// rotations, schedules and flushes.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// After returns a channel receiving the current time once d has elapsed.
+	After(d time.Duration) <-chan time.Time
+}
+
+// WithClock returns a function to set the clock of holmes, e.g. the clock of
+// a simulation to produce reproducible logs. It defaults to the system clock.
+func WithClock(c Clock) func(Logger) Logger {
+	return func(l Logger) Logger {
+		l.clock = c
+		return l
+	}
+}
+
+// systemClock is the Clock of the standard time package.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// clock is the Clock of the started logger.
+var clock Clock = systemClock{}
+
+func timeNow() time.Time {
+	return clock.Now()
+}
+
+func timeAfter(d time.Duration) <-chan time.Time {
+	return clock.After(d)
+}
@@ -0,0 +1,105 @@
+package holmes
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testClock is a Clock advanced by tests. If waits is set, it receives the
+// duration of every call to After.
+type testClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []testTimer
+	waits  chan time.Duration
+}
+
+type testTimer struct {
+	at time.Time
+	ch chan time.Time
+}
+
+func newTestClock(now time.Time) *testClock {
+	return &testClock{now: now}
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) After(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	c.mu.Lock()
+	c.timers = append(c.timers, testTimer{at: c.now.Add(d), ch: ch})
+	c.mu.Unlock()
+	if c.waits != nil {
+		c.waits <- d
+	}
+	return ch
+}
+
+// add advances c by d, firing the timers due.
+func (c *testClock) add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	pending := c.timers[:0]
+	for _, t := range c.timers {
+		if t.at.After(c.now) {
+			pending = append(pending, t)
+			continue
+		}
+		t.ch <- c.now
+	}
+	c.timers = pending
+}
+
+func TestWithClock(t *testing.T) {
+	clk := newTestClock(time.Date(2016, 7, 8, 11, 59, 30, 0, time.Local))
+	dir := t.TempDir()
+	l := Start(WithClock(clk), LogFilePath(dir), EveryMinute)
+	Infof("%s", "first")
+	clk.add(20 * time.Second)
+	Infof("%s", "second")
+	clk.add(20 * time.Second)
+	Infof("%s", "third")
+	l.Stop()
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wants := map[string][]string{
+		getLogFileName(time.Date(2016, 7, 8, 11, 59, 30, 0, time.Local)): {
+			"2016/07/08 11:59:30  INFO",
+			"2016/07/08 11:59:50  INFO",
+		},
+		getLogFileName(time.Date(2016, 7, 8, 12, 0, 10, 0, time.Local)): {
+			"2016/07/08 12:00:10  INFO",
+		},
+	}
+	if len(files) != len(wants) {
+		t.Fatalf("got files %v, want %d", files, len(wants))
+	}
+	for name, prefixes := range wants {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		if len(lines) != len(prefixes) {
+			t.Fatalf("%s: got %d records, want %d", name, len(lines), len(prefixes))
+		}
+		for i, prefix := range prefixes {
+			if !strings.HasPrefix(lines[i], prefix) {
+				t.Errorf("%s: got %q, want prefix %q", name, lines[i], prefix)
+			}
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package holmes
+
+// ComponentLevel returns a function to set the level of the records logged by
+// the loggers of the given components instead of the logger level, e.g.
+// ComponentLevel(map[string]LogLevel{"auth": DEBUG}) along with InfoLevel.
+func ComponentLevel(levels map[string]LogLevel) func(Logger) Logger {
+	return func(l Logger) Logger {
+		merged := make(map[string]LogLevel, len(l.componentLevels)+len(levels))
+		for name, level := range l.componentLevels {
+			merged[name] = level
+		}
+		for name, level := range levels {
+			merged[name] = level
+		}
+		l.componentLevels = merged
+		return l
+	}
+}
+
+// WithComponent returns the started logger for the named component, see
+// Logger.WithComponent.
+func WithComponent(name string) Logger {
+	return loggerInstance.WithComponent(name)
+}
+
+// WithComponent returns a copy of l attaching a component field to its
+// records and logging at the level of the component set by ComponentLevel,
+// if any.
+func (l Logger) WithComponent(name string) Logger {
+	fields := make([]Field, 0, len(l.requestFields)+1)
+	l.requestFields = append(append(fields, l.requestFields...), Field{Key: "component", Value: name})
+	if level, ok := l.componentLevels[name]; ok {
+		l.componentLevel = &level
+	} else {
+		l.componentLevel = nil
+	}
+	return l
+}
@@ -0,0 +1,29 @@
+package holmes
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestComponentLevel(t *testing.T) {
+	buf := &Buffer{}
+	l := Start(InfoLevel, AlsoWriter(buf), ComponentLevel(map[string]LogLevel{"auth": DEBUG, "db": ERROR}))
+	WithComponent("auth").Debugf("%s", "auth debug")
+	WithComponent("billing").Debugf("%s", "billing debug")
+	WithComponent("billing").Infof("%s", "billing info")
+	WithComponent("db").Warnf("%s", "db warn")
+	Debugf("%s", "global debug")
+	l.Stop()
+
+	got := buf.String()
+	for _, want := range []string{"- auth debug component=auth", "- billing info component=billing"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	}
+	for _, hidden := range []string{"billing debug", "db warn", "global debug"} {
+		if strings.Contains(got, hidden) {
+			t.Errorf("got %q, want %q suppressed", got, hidden)
+		}
+	}
+}
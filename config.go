@@ -0,0 +1,95 @@
+package holmes
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"time"
+)
+
+// diskFree returns the free space of the filesystem of a directory, replaced in tests.
+var diskFree = freeSpace
+
+// Config is a snapshot of the effective settings of a logger.
+type Config struct {
+	Level         LogLevel       `json:"level"`
+	LogPath       string         `json:"log_path"`
+	Unit          time.Duration  `json:"unit"`
+	MaxRecords    int            `json:"max_records"`
+	Stdout        bool           `json:"stdout"`
+	PrintStack    bool           `json:"print_stack"`
+	SummaryOnStop bool           `json:"summary_on_stop"`
+	FileLevel     LogLevel       `json:"file_level"`
+	StdoutLevel   LogLevel       `json:"stdout_level"`
+	Formatter     string         `json:"formatter"`
+	Sinks         int            `json:"sinks"`
+	Enrichers     int            `json:"enrichers"`
+	Async         int            `json:"async"`
+	Ordering      OrderingMode   `json:"ordering"`
+	Overflow      OverflowPolicy `json:"overflow"`
+}
+
+// Config returns a snapshot of the settings of l after all decorators applied.
+func (l Logger) Config() Config {
+	return Config{
+		Level:         l.minLevel(),
+		LogPath:       l.logPath,
+		Unit:          l.unit,
+		MaxRecords:    int(l.maxRecords),
+		Stdout:        l.isStdout,
+		PrintStack:    l.printStack,
+		SummaryOnStop: l.summaryOnStop,
+		FileLevel:     l.fileLevel,
+		StdoutLevel:   l.stdoutLevel,
+		Formatter:     formatterName(l.formatter),
+		Sinks:         len(l.sinks),
+		Enrichers:     len(l.enrichers),
+		Async:         l.asyncSize,
+		Ordering:      l.ordering,
+		Overflow:      l.overflow,
+	}
+}
+
+// CurrentConfig returns a snapshot of the settings of the started logger.
+func CurrentConfig() Config {
+	return loggerInstance.Config()
+}
+
+// check returns an error if the settings of l conflict with each other.
+func (l Logger) check() error {
+	if l.configErr != nil {
+		return l.configErr
+	}
+	if l.jsonPretty {
+		if _, ok := l.formatter.(JSONFormatter); !ok {
+			return errors.New("holmes: JSONPretty requires JSONFormat")
+		}
+		if l.logPath != "" || l.tracePath != "" || len(l.writers) > 0 || l.eventSource != "" {
+			return errors.New("holmes: JSONPretty can only be used with stdout or stderr, not with log files or other writers")
+		}
+	}
+	if l.tracePath != "" && l.logPath != "" && path.Clean(l.tracePath) == path.Clean(l.logPath) {
+		return errors.New("holmes: TraceSample needs another path than LogFilePath")
+	}
+	return nil
+}
+
+// checkFreeSpace returns an error if the log file path has less free space
+// than required by MinFreeSpace.
+func (l Logger) checkFreeSpace() error {
+	if l.minFreeSpace <= 0 || l.logPath == "" {
+		return nil
+	}
+	if err := os.MkdirAll(l.logPath, os.ModePerm); err != nil {
+		return err
+	}
+	free, err := diskFree(l.logPath)
+	if err != nil {
+		return fmt.Errorf("holmes: MinFreeSpace: %v", err)
+	}
+	if free < l.minFreeSpace {
+		return fmt.Errorf("holmes: %s has %d bytes free, less than the %d bytes required", l.logPath, free, l.minFreeSpace)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package holmes
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfig(t *testing.T) {
+	dir := t.TempDir()
+	l := Start(LogFilePath(dir), WarnLevel, EveryHour, AlsoStdout, MaxRecords(10), AlsoWriter(&bytes.Buffer{}))
+	got := CurrentConfig()
+	l.Stop()
+
+	want := Config{
+		Level:      WARN,
+		LogPath:    dir,
+		Unit:       time.Hour,
+		MaxRecords: 10,
+		Stdout:     true,
+		Formatter:  "text",
+		Sinks:      3,
+	}
+	if got != want {
+		t.Errorf("got config %+v, want %+v", got, want)
+	}
+	if CurrentConfig() != (Config{Formatter: "text"}) {
+		t.Errorf("got config %+v after stop", CurrentConfig())
+	}
+}
+
+func TestMinFreeSpace(t *testing.T) {
+	dir := t.TempDir()
+	free, err := freeSpace(dir)
+	if err != nil {
+		t.Skipf("free space not available: %v", err)
+	}
+	if free <= 0 {
+		t.Fatalf("got %d bytes free", free)
+	}
+	Start(LogFilePath(dir), MinFreeSpace(1)).Stop()
+
+	diskFree = func(string) (int64, error) { return 1 << 20, nil }
+	defer func() { diskFree = freeSpace }()
+	Start(LogFilePath(dir), MinFreeSpace(1<<20)).Stop()
+	func() {
+		defer func() {
+			err, _ := recover().(error)
+			if err == nil || !strings.Contains(err.Error(), "less than the 1048577 bytes required") {
+				t.Errorf("got %v, want not enough free space", err)
+			}
+		}()
+		Start(LogFilePath(dir), MinFreeSpace(1<<20+1)).Stop()
+	}()
+	Start(MinFreeSpace(1 << 40)).Stop() // no log file
+}
@@ -0,0 +1,127 @@
+package holmes
+
+import (
+	"context"
+	"fmt"
+)
+
+type contextKey struct{}
+
+// contextScope holds the logging settings carried by a context.
+type contextScope struct {
+	sinks  []sink
+	fields []Field
+}
+
+func scopeFrom(ctx context.Context) contextScope {
+	scope, _ := ctx.Value(contextKey{}).(contextScope)
+	return scope
+}
+
+// FromContext returns the started logger extended with the settings carried by ctx.
+func FromContext(ctx context.Context) Logger {
+	l := loggerInstance
+	scope := scopeFrom(ctx)
+	if len(l.sinks) > 0 && len(scope.sinks) > 0 {
+		sinks := make([]sink, 0, len(l.sinks)+len(scope.sinks))
+		l.sinks = append(append(sinks, l.sinks...), scope.sinks...)
+	}
+	l.requestFields = scope.fields
+	return l
+}
+
+// WithFields returns a copy of ctx carrying the fields given as alternating
+// keys and values, they are attached to the records logged through FromContext
+// with the returned context.
+func WithFields(ctx context.Context, keyvals ...interface{}) context.Context {
+	fields, err := fieldsOf(keyvals)
+	if err != nil {
+		internalError(fmt.Errorf("holmes: WithFields: %v", err))
+		return ctx
+	}
+	scope := scopeFrom(ctx)
+	merged := make([]Field, 0, len(scope.fields)+len(fields))
+	scope.fields = append(append(merged, scope.fields...), fields...)
+	return context.WithValue(ctx, contextKey{}, scope)
+}
+
+// CaptureScope returns a copy of ctx and a Buffer, records logged through
+// FromContext with the returned context are also written into the Buffer.
+func CaptureScope(ctx context.Context) (context.Context, *Buffer) {
+	buf := &Buffer{}
+	scope := scopeFrom(ctx)
+	sinks := make([]sink, 0, len(scope.sinks)+1)
+	scope.sinks = append(append(sinks, scope.sinks...), sink{w: buf, level: DEBUG})
+	return context.WithValue(ctx, contextKey{}, scope), buf
+}
+
+// Log prints formatted log at level, it exits if level is FATAL, see NoExitOnFatal.
+func (l Logger) Log(level LogLevel, format string, v ...interface{}) {
+	l.doPrintf(level, format, v...)
+	if level >= FATAL {
+		l.exit()
+	}
+}
+
+// Logln prints log at level, it exits if level is FATAL, see NoExitOnFatal.
+func (l Logger) Logln(level LogLevel, v ...interface{}) {
+	l.doPrintln(level, v...)
+	if level >= FATAL {
+		l.exit()
+	}
+}
+
+// Debugf prints formatted debug log.
+func (l Logger) Debugf(format string, v ...interface{}) {
+	l.Log(DEBUG, format, v...)
+}
+
+// Infof prints formatted info log.
+func (l Logger) Infof(format string, v ...interface{}) {
+	l.Log(INFO, format, v...)
+}
+
+// Warnf prints formatted warn log.
+func (l Logger) Warnf(format string, v ...interface{}) {
+	l.Log(WARN, format, v...)
+}
+
+// Errorf prints formatted error log.
+func (l Logger) Errorf(format string, v ...interface{}) {
+	l.Log(ERROR, format, v...)
+}
+
+// ErrorErr prints error log with err attached as the error field.
+func (l Logger) ErrorErr(err error, v ...interface{}) {
+	l.doPrintln(ERROR, append(v[:len(v):len(v)], Field{Key: "error", Value: err})...)
+}
+
+// Fatalf prints formatted fatal log and exits.
+func (l Logger) Fatalf(format string, v ...interface{}) {
+	l.Log(FATAL, format, v...)
+}
+
+// Debugln prints debug log.
+func (l Logger) Debugln(v ...interface{}) {
+	l.Logln(DEBUG, v...)
+}
+
+// Infoln prints info log.
+func (l Logger) Infoln(v ...interface{}) {
+	l.Logln(INFO, v...)
+}
+
+// Warnln prints warn log.
+func (l Logger) Warnln(v ...interface{}) {
+	l.Logln(WARN, v...)
+}
+
+// Errorln prints error log.
+func (l Logger) Errorln(v ...interface{}) {
+	l.Logln(ERROR, v...)
+}
+
+// Fatalln prints fatal log and exits.
+func (l Logger) Fatalln(v ...interface{}) {
+	l.Logln(FATAL, v...)
+}
@@ -0,0 +1,42 @@
+package holmes
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestCaptureScope(t *testing.T) {
+	dir := t.TempDir()
+	l := Start(LogFilePath(dir))
+	ctx, buf := CaptureScope(context.Background())
+	FromContext(ctx).Infof("%s", "inside scope")
+	FromContext(ctx).Warnln("inside scope again")
+	FromContext(context.Background()).Infof("%s", "outside scope")
+	Errorf("%s", "global record")
+	l.Stop()
+
+	global := readLogs(t, dir)
+	for _, want := range []string{"inside scope", "inside scope again", "outside scope", "global record"} {
+		if !strings.Contains(global, want) {
+			t.Errorf("global output misses %q", want)
+		}
+	}
+	scoped := buf.String()
+	if n := strings.Count(scoped, "\n"); n != 2 {
+		t.Errorf("got %d scoped records, want 2", n)
+	}
+	for _, unwanted := range []string{"outside scope", "global record"} {
+		if strings.Contains(scoped, unwanted) {
+			t.Errorf("scoped output contains %q", unwanted)
+		}
+	}
+}
+
+func TestFromContextNotStarted(t *testing.T) {
+	ctx, buf := CaptureScope(context.Background())
+	FromContext(ctx).Infof("%s", "dropped")
+	if buf.String() != "" {
+		t.Errorf("got %q from a stopped logger", buf.String())
+	}
+}
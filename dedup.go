@@ -0,0 +1,77 @@
+package holmes
+
+import (
+	"sync"
+	"time"
+)
+
+// maxDedupSites bounds the number of call sites tracked by DedupByCaller,
+// records of further sites are not deduplicated.
+const maxDedupSites = 4096
+
+// DedupByCaller returns a function to drop the records logged from a call
+// site, whatever their values, less than window after the last record logged
+// from it, e.g. a warning logged for every item of a loop. The next record
+// logged from the site has a repeated field counting the records dropped.
+func DedupByCaller(window time.Duration) func(Logger) Logger {
+	return func(l Logger) Logger {
+		l.dedup = &callerDedup{window: window, sites: make(map[callerSite]*siteState)}
+		return l
+	}
+}
+
+type callerSite struct {
+	file string
+	line int
+}
+
+type siteState struct {
+	last     time.Time
+	repeated int
+}
+
+// callerDedup tracks when the call sites last logged a record.
+type callerDedup struct {
+	window time.Duration
+	mu     sync.Mutex
+	sites  map[callerSite]*siteState
+}
+
+// allow reports whether to log a record from file and line, and the number
+// of records from the site dropped since the last one logged.
+func (d *callerDedup) allow(file string, line int) (bool, int) {
+	if d == nil {
+		return true, 0
+	}
+	now := timeNow()
+	site := callerSite{file: file, line: line}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	state, ok := d.sites[site]
+	if !ok {
+		if len(d.sites) >= maxDedupSites {
+			d.expire(now)
+			if len(d.sites) >= maxDedupSites {
+				return true, 0
+			}
+		}
+		d.sites[site] = &siteState{last: now}
+		return true, 0
+	}
+	if now.Sub(state.last) < d.window {
+		state.repeated++
+		return false, 0
+	}
+	repeated := state.repeated
+	state.last, state.repeated = now, 0
+	return true, repeated
+}
+
+// expire forgets the sites whose window is over and have no dropped records.
+func (d *callerDedup) expire(now time.Time) {
+	for site, state := range d.sites {
+		if now.Sub(state.last) >= d.window && state.repeated == 0 {
+			delete(d.sites, site)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package holmes
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDedupByCaller(t *testing.T) {
+	clk := newTestClock(time.Date(2016, 7, 8, 11, 25, 0, 0, time.Local))
+
+	buf := &Buffer{}
+	l := Start(WithClock(clk), AlsoWriter(buf), DedupByCaller(time.Minute))
+	validate := func(items []int) {
+		for _, i := range items {
+			Warnf("item %d is invalid", i)
+		}
+	}
+	validate([]int{0, 1, 2, 3, 4})
+	Warnf("item %d is invalid", 0)
+	clk.add(time.Minute)
+	validate([]int{5, 6, 7})
+	l.Stop()
+
+	wants := []string{
+		"- item 0 is invalid",
+		"- item 0 is invalid",
+		"- item 5 is invalid repeated=4",
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != len(wants) {
+		t.Fatalf("got %d records %q, want %d", len(lines), lines, len(wants))
+	}
+	for i, want := range wants {
+		if !strings.HasSuffix(lines[i], want) {
+			t.Errorf("got %q, want suffix %q", lines[i], want)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !linux && !darwin && !freebsd && !dragonfly && !windows
+// +build !linux,!darwin,!freebsd,!dragonfly,!windows
+
+package holmes
+
+import "errors"
+
+// freeSpace is not supported on this platform.
+func freeSpace(dir string) (int64, error) {
+	return 0, errors.New("free space check not supported on this platform")
+}
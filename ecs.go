@@ -0,0 +1,79 @@
+package holmes
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// ecsVersion is the version of the Elastic Common Schema of ECSFormatter.
+const ecsVersion = "1.6.0"
+
+// ecsLevel maps levels to the log.level values of ECS.
+var ecsLevel = map[LogLevel]string{
+	DEBUG: "debug",
+	INFO:  "info",
+	WARN:  "warn",
+	ERROR: "error",
+	FATAL: "fatal",
+}
+
+// ECSFormat sets log output records as Elastic Common Schema JSON objects.
+func ECSFormat(l Logger) Logger {
+	l.formatter = ECSFormatter{}
+	return l
+}
+
+// ECSFormatter renders records as one-line Elastic Common Schema JSON objects
+// for Elasticsearch and Kibana, e.g.
+// {"@timestamp":"2016-07-08T11:25:48.000+08:00","log.level":"info","message":"message","ecs.version":"1.6.0","log.origin":{"file.name":"example.go","file.line":48,"function":"example.main"},"labels":{"key":"value"}}
+// Fields are put in labels, the stack of StackOnError in error.stack_trace.
+type ECSFormatter struct{}
+
+// Format implements Formatter.
+func (ECSFormatter) Format(r *Record) []byte {
+	level, ok := ecsLevel[r.Level]
+	if !ok {
+		level = strings.ToLower(r.Level.String())
+	}
+	b := make([]byte, 0, 256+len(r.Message))
+	b = append(b, `{"@timestamp":`...)
+	b = appendJSON(b, r.Time.Format("2006-01-02T15:04:05.000Z07:00"))
+	b = append(b, `,"log.level":`...)
+	b = appendJSON(b, level)
+	b = append(b, `,"message":`...)
+	b = appendJSON(b, r.Message)
+	b = append(b, `,"ecs.version":`...)
+	b = appendJSON(b, ecsVersion)
+	if r.File != "" {
+		b = append(b, `,"log.origin":{"file.name":`...)
+		b = appendJSON(b, path.Base(r.File))
+		b = append(b, `,"file.line":`...)
+		b = strconv.AppendInt(b, int64(r.Line), 10)
+		b = append(b, `,"function":`...)
+		b = appendJSON(b, path.Base(r.Func))
+		b = append(b, '}')
+	}
+	if len(r.Fields) > 0 {
+		b = append(b, `,"labels":{`...)
+		for i, f := range r.Fields {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = appendJSON(b, f.Key)
+			b = append(b, ':')
+			b = appendJSON(b, f.Value)
+		}
+		b = append(b, '}')
+	}
+	if len(r.Stack) > 0 {
+		var trace strings.Builder
+		for _, frame := range r.Stack {
+			fmt.Fprintf(&trace, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		}
+		b = append(b, `,"error.stack_trace":`...)
+		b = appendJSON(b, trace.String())
+	}
+	return append(b, '}', '\n')
+}
@@ -0,0 +1,65 @@
+package holmes
+
+import (
+	"encoding/json"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestECSFormat(t *testing.T) {
+	buf := &Buffer{}
+	l := Start(ECSFormat, AlsoWriter(buf), BaseFields("user", "neo"))
+	_, _, line, _ := runtime.Caller(0)
+	Warnf("%s", "knock knock")
+	l.Stop()
+
+	var record map[string]interface{}
+	if err := json.Unmarshal([]byte(buf.String()), &record); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+	for _, key := range []string{"@timestamp", "log.level", "message", "ecs.version", "log.origin", "labels"} {
+		if _, ok := record[key]; !ok {
+			t.Errorf("missing %s in %q", key, buf.String())
+		}
+	}
+	if len(record) != 6 {
+		t.Errorf("got %d keys in %q, want 6", len(record), buf.String())
+	}
+	if _, err := time.Parse(time.RFC3339, record["@timestamp"].(string)); err != nil {
+		t.Errorf("invalid @timestamp: %v", err)
+	}
+	if record["log.level"] != "warn" || record["message"] != "knock knock" {
+		t.Errorf("got log.level %v and message %v", record["log.level"], record["message"])
+	}
+	origin, _ := record["log.origin"].(map[string]interface{})
+	want := map[string]interface{}{
+		"file.name": "ecs_test.go",
+		"file.line": float64(line + 1),
+		"function":  "holmes.TestECSFormat",
+	}
+	for key, value := range want {
+		if origin[key] != value {
+			t.Errorf("log.origin.%s: got %v, want %v", key, origin[key], value)
+		}
+	}
+	if labels, _ := record["labels"].(map[string]interface{}); labels["user"] != "neo" {
+		t.Errorf("got labels %v", record["labels"])
+	}
+}
+
+func TestECSFormatStack(t *testing.T) {
+	buf := &Buffer{}
+	l := Start(ECSFormat, StackOnError, AlsoWriter(buf))
+	Errorf("%s", "failed")
+	l.Stop()
+
+	var record map[string]interface{}
+	if err := json.Unmarshal([]byte(buf.String()), &record); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+	if trace, _ := record["error.stack_trace"].(string); !strings.Contains(trace, "TestECSFormatStack") {
+		t.Errorf("got error.stack_trace %q", trace)
+	}
+}
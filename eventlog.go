@@ -0,0 +1,45 @@
+package holmes
+
+import (
+	"strings"
+)
+
+// eventLogger is the subset of the Windows event log API used by holmes.
+type eventLogger interface {
+	Info(eid uint32, msg string) error
+	Warning(eid uint32, msg string) error
+	Error(eid uint32, msg string) error
+	Close() error
+}
+
+// eventLogWriter writes records to an event log, mapping the record levels
+// to the Info, Warning and Error event types.
+type eventLogWriter struct {
+	log eventLogger
+}
+
+// eventID is the event ID of all the events written by holmes.
+const eventID = 1
+
+func (w eventLogWriter) Write(p []byte) (int, error) {
+	if err := w.log.Info(eventID, strings.TrimSuffix(string(p), "\n")); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (w eventLogWriter) WriteRecord(r *Record, line []byte) error {
+	msg := strings.TrimSuffix(string(line), "\n")
+	switch {
+	case r.Level >= ERROR:
+		return w.log.Error(eventID, msg)
+	case r.Level == WARN:
+		return w.log.Warning(eventID, msg)
+	default:
+		return w.log.Info(eventID, msg)
+	}
+}
+
+func (w eventLogWriter) Close() error {
+	return w.log.Close()
+}
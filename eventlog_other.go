@@ -0,0 +1,21 @@
+//go:build !windows
+// +build !windows
+
+package holmes
+
+import (
+	"errors"
+)
+
+// WindowsEventLog returns a function to make log also output to the Windows
+// event log as source. It has no effect on other platforms than Windows.
+func WindowsEventLog(source string) func(Logger) Logger {
+	return func(l Logger) Logger {
+		return l
+	}
+}
+
+// openEventLog is never called as WindowsEventLog sets no event source here.
+var openEventLog = func(source string) (eventLogger, error) {
+	return nil, errors.New("holmes: no Windows event log on this platform")
+}
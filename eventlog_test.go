@@ -0,0 +1,61 @@
+package holmes
+
+import (
+	"strings"
+	"testing"
+)
+
+type fakeEventLog struct {
+	events []string
+	closed bool
+}
+
+func (f *fakeEventLog) Info(eid uint32, msg string) error {
+	f.events = append(f.events, "Info: "+msg)
+	return nil
+}
+
+func (f *fakeEventLog) Warning(eid uint32, msg string) error {
+	f.events = append(f.events, "Warning: "+msg)
+	return nil
+}
+
+func (f *fakeEventLog) Error(eid uint32, msg string) error {
+	f.events = append(f.events, "Error: "+msg)
+	return nil
+}
+
+func (f *fakeEventLog) Close() error {
+	f.closed = true
+	return nil
+}
+
+func TestEventLogWriter(t *testing.T) {
+	elog := &fakeEventLog{}
+	w := eventLogWriter{log: elog}
+	l := Start(func(l Logger) Logger {
+		l.writers = append(l.writers, sink{w: w, level: DEBUG, closer: w})
+		return l
+	})
+	Debugln("debug record")
+	Infoln("info record")
+	Warnln("warn record")
+	Errorln("error record")
+	l.Stop()
+
+	wants := []string{"Info: ", "Info: ", "Warning: ", "Error: "}
+	if len(elog.events) != len(wants) {
+		t.Fatalf("got %d events, want %d", len(elog.events), len(wants))
+	}
+	for i, want := range wants {
+		if !strings.HasPrefix(elog.events[i], want) {
+			t.Errorf("got event %q, want type %q", elog.events[i], want)
+		}
+	}
+	if !strings.HasSuffix(elog.events[3], "error record") {
+		t.Errorf("got event %q", elog.events[3])
+	}
+	if !elog.closed {
+		t.Error("event log not closed on stop")
+	}
+}
@@ -0,0 +1,30 @@
+//go:build windows
+// +build windows
+
+package holmes
+
+import (
+	"strings"
+
+	"golang.org/x/sys/windows/svc/eventlog"
+)
+
+// WindowsEventLog returns a function to make log also output to the Windows
+// event log as source, which is registered by Start if it doesn't exist yet.
+// Debug and info records are written as Info events, warn records as Warning
+// events, error and fatal records as Error events.
+func WindowsEventLog(source string) func(Logger) Logger {
+	return func(l Logger) Logger {
+		l.eventSource = source
+		return l
+	}
+}
+
+// openEventLog registers source if needed and opens its event log.
+var openEventLog = func(source string) (eventLogger, error) {
+	err := eventlog.InstallAsEventCreate(source, eventlog.Error|eventlog.Warning|eventlog.Info)
+	if err != nil && !strings.Contains(err.Error(), "registry key already exists") {
+		return nil, err
+	}
+	return eventlog.Open(source)
+}
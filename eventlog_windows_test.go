@@ -0,0 +1,44 @@
+//go:build windows
+// +build windows
+
+package holmes
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWindowsEventLog(t *testing.T) {
+	elog := &fakeEventLog{}
+	var sources []string
+	defer func(open func(string) (eventLogger, error)) { openEventLog = open }(openEventLog)
+	openEventLog = func(source string) (eventLogger, error) {
+		sources = append(sources, source)
+		return elog, nil
+	}
+
+	func() {
+		defer func() { recover() }()
+		Start(WindowsEventLog("holmes-test"), JSONPretty) // fails the check
+	}()
+	if len(sources) != 0 {
+		t.Fatal("event log opened by a failing Start")
+	}
+
+	l := Start(WindowsEventLog("holmes-test"))
+	if n := len(l.sinks); n != 2 {
+		t.Fatalf("got %d sinks, want 2", n)
+	}
+	Errorln("error record")
+	l.Stop()
+
+	if len(sources) != 1 || sources[0] != "holmes-test" {
+		t.Errorf("got event logs opened for %q, want [holmes-test]", sources)
+	}
+	if len(elog.events) != 1 || !strings.HasPrefix(elog.events[0], "Error: ") || !strings.HasSuffix(elog.events[0], "error record") {
+		t.Errorf("got events %q, want an Error event", elog.events)
+	}
+	if !elog.closed {
+		t.Error("event log not closed on stop")
+	}
+}
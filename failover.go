@@ -0,0 +1,153 @@
+package holmes
+
+import (
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// failoverProbeInterval is how often a failed primary log path is retried.
+const failoverProbeInterval = 10 * time.Second
+
+// FailoverPaths returns a function to make log write to files in primary
+// and, while writing there fails, e.g. on a full or unmounted disk, in
+// secondary, from Start on if the primary path cannot be opened then. The
+// primary path is retried every 10 seconds, a warning is written to the files
+// at each switch. Both paths rotate on their own.
+func FailoverPaths(primary, secondary string) func(Logger) Logger {
+	return func(l Logger) Logger {
+		l.logPath, l.failoverPath = primary, secondary
+		return l
+	}
+}
+
+// failover writes to a secondary segment while the primary one fails.
+type failover struct {
+	secondary   *logSegment
+	open        func() *logSegment
+	format      Formatter
+	location    *time.Location
+	failed      bool
+	lastProbe   time.Time
+	primaryPath string
+}
+
+func newFailover(l Logger) *failover {
+	f := l.fileFormatter
+	if f == nil {
+		f = l.formatter
+	}
+	primaryPath := l.logPath
+	l.logPath, l.failoverPath = l.failoverPath, ""
+	return &failover{
+		open:        func() *logSegment { return newLogSegment(l) },
+		format:      f,
+		location:    l.location,
+		primaryPath: primaryPath,
+	}
+}
+
+// write writes p to the primary segment ls, or to the secondary one while
+// writing to ls fails.
+func (fo *failover) write(ls *logSegment, p []byte) (int, error) {
+	if fo.failed {
+		now := timeNow()
+		if now.Sub(fo.lastProbe) < failoverProbeInterval {
+			return fo.secondary.write(p)
+		}
+		fo.lastProbe = now
+		if !fo.probe(ls) {
+			return fo.secondary.write(p)
+		}
+		fo.secondary.write(fo.marker("holmes: switching back to log path %s", fo.primaryPath))
+		fo.failed = false
+	}
+	n, err := ls.write(p)
+	if err == nil {
+		return n, nil
+	}
+	if !fo.fail(err) {
+		return n, err
+	}
+	return fo.secondary.write(p)
+}
+
+// fail switches to the secondary segment after err writing to the primary
+// one, opening the secondary segment if needed. It reports false if it cannot
+// be opened.
+func (fo *failover) fail(err error) bool {
+	if fo.secondary == nil {
+		if fo.secondary = fo.open(); fo.secondary == nil {
+			return false
+		}
+	}
+	fo.failed, fo.lastProbe = true, timeNow()
+	fo.secondary.write(fo.marker("holmes: writing to log path %s failed, switching to %s: %v", fo.primaryPath, fo.secondary.logPath, err))
+	return true
+}
+
+// probe writes a warning about the recovery to the primary segment ls,
+// reopening its log file for appending, or creating one if none could be
+// opened. A created file is removed again if the warning cannot be written,
+// so that failed probes leave no files behind. ls is only switched to the
+// file once the warning is written.
+func (fo *failover) probe(ls *logSegment) bool {
+	os.MkdirAll(ls.logPath, os.ModePerm) // e.g. after remounting the disk
+	name := ls.idleName
+	if name == "" && ls.logFile != nil && ls.logFile != os.Stderr {
+		name = ls.logFile.Name()
+	}
+	var logFile *os.File
+	var final string
+	var err error
+	switch {
+	case name != "":
+		logFile, err = os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
+	case ls.atomic:
+		logFile, final, err = createTempLogFile(ls.logPath, timeNow().In(ls.loc))
+	default:
+		logFile, err = createLogFile(ls.logPath, timeNow().In(ls.loc))
+	}
+	if err != nil {
+		return false
+	}
+	p := fo.marker("holmes: log path %s recovered", fo.primaryPath)
+	if name == "" && ls.header != nil {
+		p = append(append([]byte{}, ls.header...), p...)
+	}
+	if _, err := logFile.Write(p); err != nil {
+		logFile.Close()
+		if name == "" {
+			os.Remove(logFile.Name())
+		}
+		return false
+	}
+	if ls.logFile != nil && ls.logFile != os.Stderr && ls.idleName == "" {
+		ls.logFile.Close()
+	}
+	ls.logFile, ls.idleName = logFile, ""
+	if name == "" {
+		ls.final = final
+	}
+	if ls.batch != nil {
+		ls.batch.setWriter(logFile)
+	}
+	atomic.AddInt64(&ls.records, 1)
+	return true
+}
+
+// marker returns a warning record about a switch formatted like the records.
+func (fo *failover) marker(format string, v ...interface{}) []byte {
+	t := timeNow()
+	if fo.location != nil {
+		t = t.In(fo.location)
+	}
+	return fo.format.Format(&Record{Time: t, Level: WARN, Message: fmt.Sprintf(format, v...)})
+}
+
+func (fo *failover) close() {
+	if fo.secondary != nil {
+		fo.secondary.Close()
+	}
+}
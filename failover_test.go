@@ -0,0 +1,131 @@
+package holmes
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFailoverPaths(t *testing.T) {
+	clk := newTestClock(time.Date(2016, 7, 8, 11, 25, 0, 0, time.Local))
+
+	primary, secondary := t.TempDir(), t.TempDir()
+	l := Start(WithClock(clk), FailoverPaths(primary, secondary))
+	Infof("%s", "before")
+	l.segment.logFile.Close() // writes to the primary path fail from now on
+	Infof("%s", "during")
+	clk.add(5 * time.Second)
+	Infof("%s", "still during")
+	clk.add(5 * time.Second)
+	Infof("%s", "after")
+	l.Stop()
+
+	// the primary records are appended to the same file
+	content := readLogs(t, primary)
+	for _, want := range []string{"- before\n", "WARN holmes: log path " + primary + " recovered\n", "- after\n"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("got primary records %q, want %q", content, want)
+		}
+	}
+	if n := strings.Count(content, "\n"); n != 3 {
+		t.Errorf("got %d primary records, want 3", n)
+	}
+	wants := []string{
+		"WARN holmes: writing to log path " + primary + " failed, switching to " + secondary + ": ",
+		"- during",
+		"- still during",
+		"WARN holmes: switching back to log path " + primary,
+	}
+	lines := strings.Split(strings.TrimSpace(readLogs(t, secondary)), "\n")
+	if len(lines) != len(wants) {
+		t.Fatalf("got secondary records %q, want %d", lines, len(wants))
+	}
+	for i, want := range wants {
+		if !strings.Contains(lines[i], want) {
+			t.Errorf("got secondary record %q, want %q", lines[i], want)
+		}
+	}
+}
+
+func TestFailoverPathsAtStart(t *testing.T) {
+	clk := newTestClock(time.Date(2016, 7, 8, 11, 25, 0, 0, time.Local))
+
+	dir, secondary := t.TempDir(), t.TempDir()
+	blocker := filepath.Join(dir, "disk")
+	if err := os.WriteFile(blocker, nil, 0666); err != nil {
+		t.Fatal(err)
+	}
+	primary := filepath.Join(blocker, "logs") // cannot be created under a file
+	l := Start(WithClock(clk), FailoverPaths(primary, secondary))
+	Infof("%s", "during")
+	os.Remove(blocker)
+	clk.add(10 * time.Second)
+	Infof("%s", "after")
+	l.Stop()
+
+	wants := []string{
+		"WARN holmes: writing to log path " + primary + " failed, switching to " + secondary + ": ",
+		"- during",
+		"WARN holmes: switching back to log path " + primary,
+	}
+	lines := strings.Split(strings.TrimSpace(readLogs(t, secondary)), "\n")
+	if len(lines) != len(wants) {
+		t.Fatalf("got secondary records %q, want %d", lines, len(wants))
+	}
+	for i, want := range wants {
+		if !strings.Contains(lines[i], want) {
+			t.Errorf("got secondary record %q, want %q", lines[i], want)
+		}
+	}
+	if content := readLogs(t, primary); !strings.HasSuffix(content, "- after\n") {
+		t.Errorf("got primary records %q, want %q", content, "- after")
+	}
+}
+
+func TestFailoverPathsFailingProbes(t *testing.T) {
+	if _, err := os.Stat("/dev/full"); err != nil {
+		t.Skip("no /dev/full to fail the writes")
+	}
+	clk := newTestClock(time.Date(2016, 7, 8, 11, 25, 0, 0, time.Local))
+
+	primary, secondary := t.TempDir(), t.TempDir()
+	var rotations int32
+	l := Start(WithClock(clk), FailoverPaths(primary, secondary), OnRotate(func(oldPath, newPath string) { atomic.AddInt32(&rotations, 1) }))
+	Infof("%s", "before")
+	name := l.segment.logFile.Name()
+	os.Remove(name)
+	if err := os.Symlink("/dev/full", name); err != nil {
+		t.Skip(err)
+	}
+	l.segment.logFile.Close() // writes to the primary path fail from now on, so do the probes
+	for i := 0; i < 5; i++ {
+		Infof("%s", "during")
+		clk.add(10 * time.Second)
+	}
+	os.Remove(name)
+	Infof("%s", "after")
+	l.Stop()
+
+	files, err := filepath.Glob(filepath.Join(primary, "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0] != name {
+		t.Errorf("got primary files %q, want %q", files, name)
+	}
+	if n := atomic.LoadInt32(&rotations); n != 0 {
+		t.Errorf("got %d rotations, want none", n)
+	}
+	if n := strings.Count(readLogs(t, secondary), "- during\n"); n != 5 {
+		t.Errorf("got %d secondary records, want 5", n)
+	}
+	content := readLogs(t, primary)
+	for _, want := range []string{"WARN holmes: log path " + primary + " recovered\n", "- after\n"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("got primary records %q, want %q", content, want)
+		}
+	}
+}
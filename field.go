@@ -0,0 +1,255 @@
+package holmes
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Field is a key-value pair attached to a log record. Fields passed along
+// with the values of a log call are attached to its record instead of being
+// formatted into the message.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Metric returns a field holding a numeric measurement, e.g.
+// holmes.Infoln("request done", holmes.Metric("latency_ms", 42.5), holmes.Metric("bytes", 1024))
+// Integers and floats are rendered as JSON numbers, except NaN and infinities
+// which JSON cannot represent. Values of other types are rendered as strings.
+func Metric(key string, value interface{}) Field {
+	switch value.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, uintptr, float32, float64:
+		return Field{Key: key, Value: value}
+	}
+	return Field{Key: key, Value: fmt.Sprint(value)}
+}
+
+// Lazy is a value of a log call computed only if the record is logged, e.g.
+// holmes.Debugln("state", holmes.Lazy(func() interface{} { return dump(state) }))
+// It can also be the value of a Field.
+type Lazy func() interface{}
+
+// splitFields separates the fields from the other values of a log call,
+// evaluating the Lazy values and expanding a message template.
+func splitFields(v []interface{}) ([]interface{}, []Field) {
+	for i, value := range v {
+		if t, ok := value.(templated); ok {
+			expanded := append(append([]interface{}(nil), v[:i]...), templateArgs(t.template, t.args)...)
+			v = append(expanded, v[i+1:]...)
+			break
+		}
+	}
+	var fields []Field
+	copied := false
+	for i, value := range v {
+		switch value := value.(type) {
+		case Field:
+			if lazy, ok := value.Value.(Lazy); ok {
+				value.Value = lazy()
+			}
+			fields = append(fields, value)
+		case Lazy:
+			if !copied { // v may be the slice of the caller
+				v, copied = append([]interface{}(nil), v...), true
+			}
+			v[i] = value()
+		}
+	}
+	if len(fields) == 0 {
+		return v, nil
+	}
+	values := make([]interface{}, 0, len(v)-len(fields))
+	for _, value := range v {
+		if _, ok := value.(Field); !ok {
+			values = append(values, value)
+		}
+	}
+	return values, fields
+}
+
+// enricher produces dynamic fields for records, optionally caching them.
+type enricher struct {
+	fn      func() []Field
+	ttl     time.Duration
+	mu      sync.Mutex
+	fields  []Field
+	expires time.Time
+}
+
+func (e *enricher) get() []Field {
+	if e.ttl <= 0 {
+		return e.call()
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	now := timeNow()
+	if now.Before(e.expires) {
+		return e.fields
+	}
+	e.fields = e.call()
+	e.expires = now.Add(e.ttl)
+	return e.fields
+}
+
+func (e *enricher) call() (fields []Field) {
+	defer func() {
+		if r := recover(); r != nil {
+			internalError(fmt.Errorf("holmes: enricher panicked: %v", r))
+			fields = nil
+		}
+	}()
+	return e.fn()
+}
+
+// Enrich returns a function to merge the fields returned by fn into every record.
+// If ttl is positive, the fields are cached and fn is invoked at most once per ttl.
+func Enrich(fn func() []Field, ttl time.Duration) func(Logger) Logger {
+	return func(l Logger) Logger {
+		l.enrichers = append(l.enrichers, &enricher{fn: fn, ttl: ttl})
+		return l
+	}
+}
+
+// BaseFields returns a function to attach the fields given as alternating
+// keys and values to every record, e.g. BaseFields("service", "auth", "region", "eu").
+// Fields of the same key attached to a request or a log call override them.
+func BaseFields(keyvals ...interface{}) func(Logger) Logger {
+	fields, err := fieldsOf(keyvals)
+	return func(l Logger) Logger {
+		if err != nil {
+			l.configErr = fmt.Errorf("holmes: BaseFields: %v", err)
+		}
+		l.baseFields = append(l.baseFields, fields...)
+		return l
+	}
+}
+
+// fieldsOf converts alternating keys and values into fields.
+func fieldsOf(keyvals []interface{}) ([]Field, error) {
+	if len(keyvals)%2 != 0 {
+		return nil, fmt.Errorf("odd number of keys and values: %d", len(keyvals))
+	}
+	fields := make([]Field, 0, len(keyvals)/2)
+	for i := 0; i < len(keyvals); i += 2 {
+		key, ok := keyvals[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("key %v is not a string", keyvals[i])
+		}
+		fields = append(fields, Field{Key: key, Value: keyvals[i+1]})
+	}
+	return fields, nil
+}
+
+// fields merges the fields attached to a record by l with the fields of the
+// log call, base fields first and later fields overriding those of the same key,
+// and applies MaxFields and MaxFieldValueLength.
+func (l Logger) fields(call []Field) []Field {
+	fields := make([]Field, 0, len(l.baseFields)+len(l.requestFields)+len(call))
+	fields = append(fields, l.baseFields...)
+	for _, e := range l.enrichers {
+		fields = append(fields, e.get()...)
+	}
+	fields = append(fields, l.requestFields...)
+	fields = append(fields, call...)
+	fields = dedupFields(fields)
+	if l.maxFields > 0 && len(fields) > l.maxFields {
+		truncated := len(fields) - l.maxFields
+		fields = append(fields[:l.maxFields:l.maxFields], Field{Key: "fields_truncated", Value: truncated})
+	}
+	if l.maxValueLength > 0 {
+		for i, f := range fields {
+			fields[i].Value = truncateValue(f.Value, l.maxValueLength)
+		}
+	}
+	return fields
+}
+
+// MaxFieldValueLength returns a function to truncate the string values of
+// fields longer than n bytes, e.g. "aGVsbG8…(4096 bytes)". Strings, byte slices,
+// errors and fmt.Stringer values are truncated, values of other types are kept.
+func MaxFieldValueLength(n int) func(Logger) Logger {
+	return func(l Logger) Logger {
+		l.maxValueLength = n
+		return l
+	}
+}
+
+// truncateValue returns the string form of v cut to n bytes on a character
+// boundary if it is longer, v itself otherwise.
+func truncateValue(v interface{}, n int) interface{} {
+	var s string
+	switch v := v.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	case error:
+		s = v.Error()
+	case fmt.Stringer:
+		s = v.String()
+	default:
+		return v
+	}
+	if len(s) <= n {
+		return v
+	}
+	cut := n
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s…(%d bytes)", s[:cut], len(s))
+}
+
+// MaxFields returns a function to limit the number of fields of a record to
+// n, dropping the last ones and adding a fields_truncated field counting them.
+func MaxFields(n int) func(Logger) Logger {
+	return func(l Logger) Logger {
+		l.maxFields = n
+		return l
+	}
+}
+
+// dedupFields removes the fields overridden by a later field of the same key.
+func dedupFields(fields []Field) []Field {
+	if len(fields) < 2 {
+		return fields
+	}
+	last := make(map[string]int, len(fields))
+	for i, f := range fields {
+		last[f.Key] = i
+	}
+	if len(last) == len(fields) {
+		return fields
+	}
+	kept := make([]Field, 0, len(last))
+	for i, f := range fields {
+		if last[f.Key] == i {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// appendFields renders fields as key=value pairs.
+func appendFields(b []byte, fields []Field) []byte {
+	for _, f := range fields {
+		b = append(b, ' ')
+		b = append(b, f.Key...)
+		b = append(b, '=')
+		b = append(b, formatValue(f.Value)...)
+	}
+	return b
+}
+
+func formatValue(v interface{}) string {
+	s := fmt.Sprint(v)
+	if s == "" || strings.ContainsAny(s, " =\"\n") {
+		return strconv.Quote(s)
+	}
+	return s
+}
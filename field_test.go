@@ -0,0 +1,235 @@
+package holmes
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEnrich(t *testing.T) {
+	dir := t.TempDir()
+	enrich := func() []Field {
+		return []Field{{Key: "requests", Value: 42}, {Key: "flag", Value: "on"}}
+	}
+	l := Start(LogFilePath(dir), Enrich(enrich, 0))
+	Infof("%s", "enriched")
+	Warnln("enriched", "again")
+	l.Stop()
+
+	lines := strings.Split(strings.TrimSpace(readLogs(t, dir)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2", len(lines))
+	}
+	for _, line := range lines {
+		if !strings.HasSuffix(line, " requests=42 flag=on") {
+			t.Errorf("line %q misses enrichment fields", line)
+		}
+	}
+}
+
+func TestEnrichTTL(t *testing.T) {
+	dir := t.TempDir()
+	var calls int32
+	enrich := func() []Field {
+		atomic.AddInt32(&calls, 1)
+		return []Field{{Key: "mem", Value: 1024}}
+	}
+	l := Start(LogFilePath(dir), Enrich(enrich, time.Hour))
+	for i := 0; i < 100; i++ {
+		Infof("%d", i)
+	}
+	l.Stop()
+
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("enricher called %d times, want 1", n)
+	}
+	if n := strings.Count(readLogs(t, dir), "mem=1024"); n != 100 {
+		t.Errorf("got %d enriched records, want 100", n)
+	}
+}
+
+func TestEnrichPanic(t *testing.T) {
+	dir := t.TempDir()
+	enrich := func() []Field {
+		panic("boom")
+	}
+	l := Start(LogFilePath(dir), Enrich(enrich, 0))
+	Infof("%s", "still logged")
+	l.Stop()
+
+	if !strings.Contains(readLogs(t, dir), "still logged") {
+		t.Error("record lost after enricher panic")
+	}
+}
+
+func TestCallFields(t *testing.T) {
+	buf := &Buffer{}
+	l := Start(AlsoWriter(buf))
+	Infof("%s %d", "answer", 42, Field{Key: "user", Value: "neo"})
+	Infoln("answer", Field{Key: "user", Value: "neo"}, 42)
+	l.Stop()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2", len(lines))
+	}
+	for _, line := range lines {
+		if !strings.HasSuffix(line, "- answer 42 user=neo") {
+			t.Errorf("got %q", line)
+		}
+	}
+}
+
+func TestBaseFields(t *testing.T) {
+	buf := &Buffer{}
+	l := Start(AlsoWriter(buf), BaseFields("service", "auth", "env", "prod"))
+	Infof("%s", "first")
+	Warnln("second")
+	ctx := WithFields(context.Background(), "env", "staging", "request", 7)
+	FromContext(ctx).Errorf("%s", "overridden")
+	l.Stop()
+
+	wants := []string{
+		"- first service=auth env=prod",
+		"- second service=auth env=prod",
+		"- overridden service=auth env=staging request=7",
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != len(wants) {
+		t.Fatalf("got %d lines, want %d", len(lines), len(wants))
+	}
+	for i, want := range wants {
+		if !strings.HasSuffix(lines[i], want) {
+			t.Errorf("got %q, want suffix %q", lines[i], want)
+		}
+	}
+}
+
+func TestBaseFieldsInvalid(t *testing.T) {
+	tests := [][]interface{}{
+		{"service"},
+		{"service", "auth", "env"},
+		{42, "answer"},
+	}
+	for _, keyvals := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("BaseFields(%v) accepted", keyvals)
+				}
+			}()
+			Start(BaseFields(keyvals...)).Stop()
+		}()
+	}
+}
+
+func TestLazy(t *testing.T) {
+	buf := &Buffer{}
+	calls := 0
+	expensive := Lazy(func() interface{} {
+		calls++
+		return "computed"
+	})
+	l := Start(InfoLevel, AlsoWriter(buf))
+	Debugln("skipped", expensive, Field{Key: "state", Value: expensive})
+	if calls != 0 {
+		t.Errorf("lazy values evaluated %d times for a suppressed record", calls)
+	}
+	args := []interface{}{"value", expensive}
+	Infof("%s %s", append(args, Field{Key: "state", Value: expensive})...)
+	l.Stop()
+
+	if calls != 2 {
+		t.Errorf("lazy values evaluated %d times, want 2", calls)
+	}
+	if want := "- value computed state=computed\n"; !strings.HasSuffix(buf.String(), want) {
+		t.Errorf("got %q, want suffix %q", buf.String(), want)
+	}
+	if _, ok := args[1].(Lazy); !ok {
+		t.Error("lazy value of the caller replaced")
+	}
+}
+
+func TestMetric(t *testing.T) {
+	buf := &Buffer{}
+	l := Start(JSONFormat, AlsoWriter(buf))
+	Infoln("request done", Metric("latency_ms", 42.5), Metric("bytes", 1024), Metric("ratio", float32(0.25)), Metric("status", "ok"))
+	l.Stop()
+
+	want := `"msg":"request done","latency_ms":42.5,"bytes":1024,"ratio":0.25,"status":"ok"}`
+	if !strings.HasSuffix(strings.TrimSpace(buf.String()), want) {
+		t.Errorf("got %q, want suffix %q", buf.String(), want)
+	}
+	decoder := json.NewDecoder(strings.NewReader(buf.String()))
+	decoder.UseNumber()
+	var record map[string]interface{}
+	if err := decoder.Decode(&record); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{"latency_ms": "42.5", "bytes": "1024", "ratio": "0.25"} {
+		if n, ok := record[key].(json.Number); !ok || n.String() != want {
+			t.Errorf("%s: got %#v, want the number %s", key, record[key], want)
+		}
+	}
+	if _, err := record["bytes"].(json.Number).Int64(); err != nil {
+		t.Errorf("bytes is not an integer: %v", err)
+	}
+}
+
+func TestMetricText(t *testing.T) {
+	buf := &Buffer{}
+	l := Start(AlsoWriter(buf))
+	Infoln("request done", Metric("latency_ms", 42.5), Metric("bytes", 1024))
+	l.Stop()
+
+	if want := "- request done latency_ms=42.5 bytes=1024\n"; !strings.HasSuffix(buf.String(), want) {
+		t.Errorf("got %q, want suffix %q", buf.String(), want)
+	}
+}
+
+func TestMaxFields(t *testing.T) {
+	buf := &Buffer{}
+	l := Start(AlsoWriter(buf), BaseFields("service", "auth"), MaxFields(3))
+	Infoln("few", Field{Key: "a", Value: 1}, Field{Key: "b", Value: 2})
+	Infoln("many", Field{Key: "a", Value: 1}, Field{Key: "b", Value: 2}, Field{Key: "c", Value: 3}, Field{Key: "d", Value: 4})
+	l.Stop()
+
+	wants := []string{
+		"- few service=auth a=1 b=2",
+		"- many service=auth a=1 b=2 fields_truncated=2",
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != len(wants) {
+		t.Fatalf("got %d lines, want %d", len(lines), len(wants))
+	}
+	for i, want := range wants {
+		if !strings.HasSuffix(lines[i], want) {
+			t.Errorf("got %q, want suffix %q", lines[i], want)
+		}
+	}
+}
+
+func TestMaxFieldValueLength(t *testing.T) {
+	blob := strings.Repeat("a", 100)
+	tests := []struct {
+		decorator func(Logger) Logger
+		want      string
+	}{
+		{WithFormatter(TextFormatter{}), ` blob="aaaaaaaa…(100 bytes)" name="hééé…(9 bytes)" short=ok count=123456789`},
+		{JSONFormat, `"blob":"aaaaaaaa…(100 bytes)","name":"hééé…(9 bytes)","short":"ok","count":123456789}`},
+	}
+	for _, test := range tests {
+		buf := &Buffer{}
+		l := Start(test.decorator, AlsoWriter(buf), MaxFieldValueLength(8))
+		Infoln("values", Field{Key: "blob", Value: blob}, Field{Key: "name", Value: "héééé"}, Field{Key: "short", Value: "ok"}, Field{Key: "count", Value: 123456789})
+		l.Stop()
+
+		got := strings.TrimSpace(buf.String())
+		if !strings.HasSuffix(got, test.want) {
+			t.Errorf("got %q, want suffix %q", got, test.want)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package holmes
+
+import (
+	"regexp"
+	"strings"
+)
+
+// fileFilter decides whether records from a source file are logged.
+type fileFilter struct {
+	allow []*regexp.Regexp
+	deny  []*regexp.Regexp
+}
+
+// FileFilter returns a function to filter records by the file they are logged from.
+// Patterns are globs matched against the trailing path elements of the file,
+// e.g. "server.go" or "handler/*.go". If allow is not empty only records from
+// matching files are logged, records from files matching deny are dropped.
+func FileFilter(allow, deny []string) func(Logger) Logger {
+	filter := &fileFilter{
+		allow: compileGlobs(allow),
+		deny:  compileGlobs(deny),
+	}
+	return func(l Logger) Logger {
+		l.fileFilter = filter
+		return l
+	}
+}
+
+func (f *fileFilter) allows(file string) bool {
+	if f == nil {
+		return true
+	}
+	if len(f.allow) > 0 && !matchAny(f.allow, file) {
+		return false
+	}
+	return !matchAny(f.deny, file)
+}
+
+func matchAny(patterns []*regexp.Regexp, file string) bool {
+	for _, pattern := range patterns {
+		if pattern.MatchString(file) {
+			return true
+		}
+	}
+	return false
+}
+
+func compileGlobs(globs []string) []*regexp.Regexp {
+	patterns := make([]*regexp.Regexp, 0, len(globs))
+	for _, glob := range globs {
+		var b strings.Builder
+		b.WriteString("(^|/)")
+		for _, c := range glob {
+			switch c {
+			case '*':
+				b.WriteString("[^/]*")
+			case '?':
+				b.WriteString("[^/]")
+			default:
+				b.WriteString(regexp.QuoteMeta(string(c)))
+			}
+		}
+		b.WriteString("$")
+		patterns = append(patterns, regexp.MustCompile(b.String()))
+	}
+	return patterns
+}
@@ -0,0 +1,58 @@
+package holmes
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFileFilterDeny(t *testing.T) {
+	dir := t.TempDir()
+	l := Start(LogFilePath(dir), FileFilter(nil, []string{"filter_test.go"}))
+	Debugf("%s", "denied debug")
+	logDebug("debug from helper")
+	l.Stop()
+
+	content := readLogs(t, dir)
+	if strings.Contains(content, "denied debug") {
+		t.Error("record from denied file logged")
+	}
+	if !strings.Contains(content, "debug from helper") {
+		t.Error("record from other file suppressed")
+	}
+}
+
+func TestFileFilterAllow(t *testing.T) {
+	dir := t.TempDir()
+	l := Start(LogFilePath(dir), FileFilter([]string{"filter_*.go"}, nil))
+	Debugf("%s", "allowed debug")
+	logDebug("debug from helper")
+	l.Stop()
+
+	content := readLogs(t, dir)
+	if !strings.Contains(content, "allowed debug") {
+		t.Error("record from allowed file suppressed")
+	}
+	if strings.Contains(content, "debug from helper") {
+		t.Error("record from other file logged")
+	}
+}
+
+func TestCompileGlobs(t *testing.T) {
+	tests := []struct {
+		glob  string
+		file  string
+		match bool
+	}{
+		{"server.go", "/src/app/server.go", true},
+		{"server.go", "/src/app/myserver.go", false},
+		{"*.go", "/src/app/server.go", true},
+		{"app/*.go", "/src/app/server.go", true},
+		{"app/*.go", "/src/app/sub/server.go", false},
+		{"serv?r.go", "/src/app/server.go", true},
+	}
+	for _, test := range tests {
+		if got := compileGlobs([]string{test.glob})[0].MatchString(test.file); got != test.match {
+			t.Errorf("%q matching %q: got %t, want %t", test.glob, test.file, got, test.match)
+		}
+	}
+}
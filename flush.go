@@ -0,0 +1,105 @@
+package holmes
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// FlushMode controls when the records of a level reach the log file.
+type FlushMode int
+
+const (
+	// Immediate writes the record, and the batched records before it, to the
+	// log file right away.
+	Immediate FlushMode = iota
+	// Batched buffers the record, writing it along with the next Immediate
+	// record or after at most a second.
+	Batched
+)
+
+// flushInterval is how often the records buffered by FlushPolicy are written.
+const flushInterval = time.Second
+
+// FlushPolicy returns a function to set when the records of each level are
+// written to the log file, e.g. FlushPolicy(map[LogLevel]FlushMode{DEBUG: Batched, INFO: Batched})
+// to batch the chatty levels while errors are written right away. Levels not
+// in modes are Immediate. A crash loses the batched records of the last second,
+// and errors writing them are only reported on stderr, not to FailoverPaths.
+func FlushPolicy(modes map[LogLevel]FlushMode) func(Logger) Logger {
+	policy := make(map[LogLevel]FlushMode, len(modes))
+	for level, mode := range modes {
+		policy[level] = mode
+	}
+	return func(l Logger) Logger {
+		l.flushModes = policy
+		return l
+	}
+}
+
+// batcher buffers the records written to a log file, flushing them every
+// flushInterval in the background.
+type batcher struct {
+	modes map[LogLevel]FlushMode
+	mu    sync.Mutex
+	w     io.Writer
+	buf   []byte
+	quit  chan struct{}
+	done  chan struct{}
+}
+
+func newBatcher(modes map[LogLevel]FlushMode, w io.Writer) *batcher {
+	b := &batcher{
+		modes: modes,
+		w:     w,
+		quit:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	go b.run()
+	return b
+}
+
+func (b *batcher) run() {
+	defer close(b.done)
+	for {
+		select {
+		case <-timeAfter(flushInterval):
+			b.flush()
+		case <-b.quit:
+			return
+		}
+	}
+}
+
+func (b *batcher) write(p []byte) {
+	b.mu.Lock()
+	b.buf = append(b.buf, p...)
+	b.mu.Unlock()
+}
+
+// flush writes the buffered records.
+func (b *batcher) flush() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.buf) == 0 {
+		return
+	}
+	if _, err := b.w.Write(b.buf); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	b.buf = b.buf[:0]
+}
+
+// setWriter makes b write to w once the log file changed.
+func (b *batcher) setWriter(w io.Writer) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.w = w
+}
+
+func (b *batcher) stop() {
+	close(b.quit)
+	<-b.done
+}
@@ -0,0 +1,251 @@
+package holmes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Record is a log record handed to a Formatter or delivered by Channel. Its
+// fields are stable, later versions only add new ones.
+type Record struct {
+	Time    time.Time
+	Level   LogLevel
+	Func    string
+	File    string
+	Line    int
+	Message string
+	Fields  []Field
+	Stack   []Frame
+
+	drops     uint64 // number of dropped records reported by a drop warning
+	always    bool   // matched by AlwaysEmit
+	traceOnly bool   // sampled by TraceSample below the level of the logger
+}
+
+// Formatter renders a record into a line written to the sinks.
+type Formatter interface {
+	Format(r *Record) []byte
+}
+
+// WithFormatter returns a function to set the formatter of the records.
+func WithFormatter(f Formatter) func(Logger) Logger {
+	return func(l Logger) Logger {
+		l.formatter = f
+		return l
+	}
+}
+
+// FileFormat returns a function to set the formatter of the records written
+// to the log file, or stdout/stderr when no log file is used.
+func FileFormat(f Formatter) func(Logger) Logger {
+	return func(l Logger) Logger {
+		l.fileFormatter = f
+		return l
+	}
+}
+
+// StdoutFormat returns a function to set the formatter of the records written
+// to stdout by AlsoStdout, overriding FileFormat when no log file is used.
+func StdoutFormat(f Formatter) func(Logger) Logger {
+	return func(l Logger) Logger {
+		l.stdoutFormatter = f
+		return l
+	}
+}
+
+// JSONFormat sets log output records as JSON objects.
+func JSONFormat(l Logger) Logger {
+	l.formatter = JSONFormatter{}
+	return l
+}
+
+// TextFormatter renders records as human-readable lines, e.g.
+// 2016/07/08 11:25:48  INFO [example.main] (example.go:48) - message key=value
+// If Color is set, the level is colored with ANSI escape codes for terminals.
+type TextFormatter struct {
+	Color bool
+}
+
+var levelColor = map[LogLevel]string{
+	DEBUG: "\x1b[36m",
+	INFO:  "\x1b[32m",
+	WARN:  "\x1b[33m",
+	ERROR: "\x1b[31m",
+	FATAL: "\x1b[35m",
+}
+
+// Format implements Formatter.
+func (f TextFormatter) Format(r *Record) []byte {
+	b := make([]byte, 0, 128+len(r.Message))
+	b = r.Time.AppendFormat(b, "2006/01/02 15:04:05 ")
+	tag := fmt.Sprintf("%5s", r.Level.String())
+	if f.Color {
+		tag = levelColor[r.Level] + tag + "\x1b[0m"
+	}
+	b = append(b, tag...)
+	if r.File != "" {
+		b = append(b, fmt.Sprintf(" [%s] (%s:%d) -", path.Base(r.Func), path.Base(r.File), r.Line)...)
+	}
+	b = append(b, ' ')
+	b = append(b, r.Message...)
+	b = appendFields(b, r.Fields)
+	for _, frame := range r.Stack {
+		b = append(b, "\n\t"...)
+		b = append(b, frame.Function...)
+		b = append(b, "\n\t\t"...)
+		b = append(b, frame.File...)
+		b = append(b, ':')
+		b = strconv.AppendInt(b, int64(frame.Line), 10)
+	}
+	return append(b, '\n')
+}
+
+// JSONPretty sets log output records as indented JSON objects spanning
+// several lines. It requires JSONFormat and can only be used with stdout or
+// stderr, as line-oriented outputs would split the records.
+func JSONPretty(l Logger) Logger {
+	l.jsonPretty = true
+	return l
+}
+
+// JSONFormatter renders records as one-line JSON objects, e.g.
+// {"time":"2016-07-08T11:25:48+08:00","level":"INFO","func":"example.main","file":"example.go","line":48,"msg":"message","key":"value"}
+// If Indent is set, records are indented with it and span several lines.
+type JSONFormatter struct {
+	Indent string
+}
+
+// Format implements Formatter.
+func (f JSONFormatter) Format(r *Record) []byte {
+	b := make([]byte, 0, 256+len(r.Message))
+	b = append(b, `{"time":`...)
+	b = appendJSON(b, r.Time.Format(time.RFC3339Nano))
+	b = append(b, `,"level":`...)
+	b = appendJSON(b, r.Level.String())
+	if r.File != "" {
+		b = append(b, `,"func":`...)
+		b = appendJSON(b, path.Base(r.Func))
+		b = append(b, `,"file":`...)
+		b = appendJSON(b, path.Base(r.File))
+		b = append(b, `,"line":`...)
+		b = strconv.AppendInt(b, int64(r.Line), 10)
+	}
+	b = append(b, `,"msg":`...)
+	b = appendJSON(b, r.Message)
+	for _, f := range r.Fields {
+		b = append(b, ',')
+		b = appendJSON(b, f.Key)
+		b = append(b, ':')
+		b = appendJSON(b, f.Value)
+	}
+	if len(r.Stack) > 0 {
+		b = append(b, `,"stack":`...)
+		b = appendJSON(b, r.Stack)
+	}
+	b = append(b, '}')
+	if f.Indent != "" {
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, b, "", f.Indent); err == nil {
+			b = indented.Bytes()
+		}
+	}
+	return append(b, '\n')
+}
+
+// schemaVersion identifies the layout of the records in SchemaHeader.
+const schemaVersion = "holmes/1"
+
+// SchemaHeader sets the log files begin with a header line describing the
+// layout of their records, so that parsers can configure themselves, e.g.
+// {"_schema":"holmes/1","format":"json","fields":["time","level","func","file","line","msg"]}
+// for JSON records or
+// # holmes/1 format=text fields=time,level,func,file,line,msg
+// for text records. Structured fields follow these in the records.
+func SchemaHeader(l Logger) Logger {
+	l.schemaHeader = true
+	return l
+}
+
+// fileSchemaHeader returns the header line of the log files.
+func (l Logger) fileSchemaHeader() []byte {
+	f := l.fileFormatter
+	if f == nil {
+		f = l.formatter
+	}
+	name := formatterName(f)
+	fields := []string{"time", "level", "func", "file", "line", "msg"}
+	if _, ok := f.(ECSFormatter); ok {
+		fields = []string{"@timestamp", "log.level", "message", "ecs.version", "log.origin"}
+	}
+	if name != "text" {
+		b := append([]byte(`{"_schema":`), appendJSON(nil, schemaVersion)...)
+		b = append(b, `,"format":`...)
+		b = appendJSON(b, name)
+		b = append(b, `,"fields":`...)
+		b = appendJSON(b, fields)
+		return append(b, '}', '\n')
+	}
+	return []byte(fmt.Sprintf("# %s format=%s fields=%s\n", schemaVersion, name, strings.Join(fields, ",")))
+}
+
+// formattedLines keeps the lines of a record formatted so far, so that sinks
+// sharing a formatter share the line.
+type formattedLines struct {
+	formatters [4]Formatter
+	lines      [4][]byte
+	n          int
+}
+
+// format returns the line of r formatted by f, formatting it unless f already
+// did. Formatters of types which are not comparable always format.
+func (c *formattedLines) format(f Formatter, r *Record) []byte {
+	comparable := reflect.TypeOf(f).Comparable()
+	if comparable {
+		for i := 0; i < c.n; i++ {
+			if c.formatters[i] == f {
+				return c.lines[i]
+			}
+		}
+	}
+	line := f.Format(r)
+	if comparable && c.n < len(c.formatters) {
+		c.formatters[c.n], c.lines[c.n] = f, line
+		c.n++
+	}
+	return line
+}
+
+// appendJSON appends the JSON encoding of v, or of its string form if v
+// cannot be encoded. Errors are encoded as their message.
+func appendJSON(b []byte, v interface{}) []byte {
+	if err, ok := v.(error); ok {
+		if _, ok := v.(json.Marshaler); !ok {
+			v = err.Error()
+		}
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprint(v))
+	}
+	return append(b, data...)
+}
+
+// formatterName returns the name of f shown in Config.
+func formatterName(f Formatter) string {
+	switch f.(type) {
+	case nil, TextFormatter:
+		return "text"
+	case JSONFormatter:
+		return "json"
+	case ECSFormatter:
+		return "ecs"
+	default:
+		return fmt.Sprintf("%T", f)
+	}
+}
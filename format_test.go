@@ -0,0 +1,291 @@
+package holmes
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestJSONFormat(t *testing.T) {
+	buf := &Buffer{}
+	fields := func() []Field {
+		return []Field{{Key: "user", Value: "neo"}, {Key: "attempts", Value: 3}}
+	}
+	l := Start(JSONFormat, AlsoWriter(buf), Enrich(fields, 0))
+	Warnf("%s", "knock \"knock\"")
+	l.Stop()
+
+	var record map[string]interface{}
+	if err := json.Unmarshal([]byte(buf.String()), &record); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+	want := map[string]interface{}{
+		"level":    "WARN",
+		"func":     "holmes.TestJSONFormat",
+		"file":     "format_test.go",
+		"msg":      "knock \"knock\"",
+		"user":     "neo",
+		"attempts": float64(3),
+	}
+	for key, value := range want {
+		if record[key] != value {
+			t.Errorf("%s: got %v, want %v", key, record[key], value)
+		}
+	}
+	if _, ok := record["time"]; !ok {
+		t.Error("missing time")
+	}
+}
+
+func TestTextFormat(t *testing.T) {
+	buf := &Buffer{}
+	fields := func() []Field {
+		return []Field{{Key: "user", Value: "neo"}, {Key: "quote", Value: "follow me"}}
+	}
+	l := Start(AlsoWriter(buf), Enrich(fields, 0))
+	_, _, line, _ := runtime.Caller(0)
+	Infof("%s\n", "wake up")
+	l.Stop()
+
+	want := fmt.Sprintf(` INFO [holmes.TestTextFormat] (format_test.go:%d) - wake up user=neo quote="follow me"`+"\n", line+1)
+	if !strings.HasSuffix(buf.String(), want) {
+		t.Errorf("got %q, want suffix %q", buf.String(), want)
+	}
+}
+
+func TestJSONPretty(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	l := Start(JSONFormat, JSONPretty, AlsoStdout)
+	os.Stdout = stdout
+	Infof("%s", "pretty")
+	l.Stop()
+	w.Close()
+	output, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(string(output), "{\n  \"time\": ") {
+		t.Errorf("got %q, want indented JSON", output)
+	}
+	var record map[string]interface{}
+	if err := json.Unmarshal(output, &record); err != nil {
+		t.Fatalf("invalid JSON %q: %v", output, err)
+	}
+	if record["msg"] != "pretty" {
+		t.Errorf("got msg %v", record["msg"])
+	}
+}
+
+func TestJSONPrettyConflicts(t *testing.T) {
+	tests := []struct {
+		name       string
+		decorators []func(Logger) Logger
+		want       string
+	}{
+		{"text", []func(Logger) Logger{JSONPretty}, "requires JSONFormat"},
+		{"framed", []func(Logger) Logger{JSONPretty, JSONFormat, AlsoWriter(FramedWriter(&Buffer{}))}, "only be used with stdout or stderr"},
+		{"file", []func(Logger) Logger{JSONFormat, JSONPretty, LogFilePath(t.TempDir())}, "only be used with stdout or stderr"},
+	}
+	for _, test := range tests {
+		func() {
+			defer func() {
+				err, _ := recover().(error)
+				if err == nil || !strings.Contains(err.Error(), test.want) {
+					t.Errorf("%s: got %v, want error containing %q", test.name, err, test.want)
+				}
+			}()
+			Start(test.decorators...).Stop()
+		}()
+	}
+	Start().Stop() // a rejected Start can be retried
+}
+
+func TestFormatPerSink(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	stdout := os.Stdout
+	os.Stdout = w
+	l := Start(LogFilePath(dir), FileFormat(JSONFormatter{}), AlsoStdout, StdoutFormat(TextFormatter{Color: true}))
+	os.Stdout = stdout
+	Infof("%s", "first")
+	Errorln("second")
+	l.Stop()
+	w.Close()
+	output, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wants := []string{"first", "second"}
+	lines := strings.Split(strings.TrimSpace(readLogs(t, dir)), "\n")
+	if len(lines) != len(wants) {
+		t.Fatalf("got %d file records, want %d", len(lines), len(wants))
+	}
+	for i, line := range lines {
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("invalid NDJSON line %q: %v", line, err)
+		}
+		if record["msg"] != wants[i] {
+			t.Errorf("got msg %v, want %s", record["msg"], wants[i])
+		}
+	}
+	lines = strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) != len(wants) {
+		t.Fatalf("got %d stdout records, want %d", len(lines), len(wants))
+	}
+	if !strings.Contains(lines[0], "\x1b[32m INFO\x1b[0m [") || !strings.HasSuffix(lines[0], "- first") {
+		t.Errorf("got stdout record %q", lines[0])
+	}
+	if !strings.Contains(lines[1], "\x1b[31mERROR\x1b[0m [") || !strings.HasSuffix(lines[1], "- second") {
+		t.Errorf("got stdout record %q", lines[1])
+	}
+}
+
+func TestFormatStdoutOnly(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	l := Start(AlsoStdout, StdoutFormat(JSONFormatter{}), StdoutLevel(WARN))
+	os.Stdout = stdout
+	Infof("%s", "first")
+	Warnln("second")
+	l.Stop()
+	w.Close()
+	output, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("got stdout records %q, want the warn one", lines)
+	}
+	var record map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("invalid JSON line %q: %v", lines[0], err)
+	}
+	if record["msg"] != "second" {
+		t.Errorf("got msg %v, want second", record["msg"])
+	}
+}
+
+// countingFormatter formats records as text, counting its calls.
+type countingFormatter struct {
+	calls *int32
+}
+
+func (f countingFormatter) Format(r *Record) []byte {
+	atomic.AddInt32(f.calls, 1)
+	return TextFormatter{}.Format(r)
+}
+
+func TestFormatOncePerFormatter(t *testing.T) {
+	var textCalls, otherCalls int32
+	text, other := countingFormatter{&textCalls}, countingFormatter{&otherCalls}
+	dir := t.TempDir()
+	first, second, third := &Buffer{}, &Buffer{}, &Buffer{}
+	l := Start(WithFormatter(text), LogFilePath(dir), FileFormat(JSONFormatter{}),
+		AlsoWriter(first), AlsoWriter(second), AlsoWriterFormat(third, text), AlsoWriterFormat(io.Discard, other))
+	Infof("%s", "first")
+	Warnln("second")
+	l.Stop()
+
+	if textCalls != 2 || otherCalls != 2 {
+		t.Errorf("got %d and %d calls of the formatters, want 2 each", textCalls, otherCalls)
+	}
+	for _, buf := range []*Buffer{first, second, third} {
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if len(lines) != 2 || !strings.HasSuffix(lines[0], "- first") || !strings.HasSuffix(lines[1], "- second") {
+			t.Errorf("got text records %q", lines)
+		}
+	}
+	lines := strings.Split(strings.TrimSpace(readLogs(t, dir)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d file records, want 2", len(lines))
+	}
+	var record map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[1]), &record); err != nil {
+		t.Fatalf("invalid JSON %q: %v", lines[1], err)
+	}
+	if record["msg"] != "second" || record["level"] != "WARN" {
+		t.Errorf("got file record %v", record)
+	}
+}
+
+func benchmarkDestinations(b *testing.B, decorators ...func(Logger) Logger) {
+	decorators = append(decorators, FileLevel(FATAL))
+	defer Start(decorators...).Stop()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Infoln("Wake up, Neo", Field{Key: "user", Value: "neo"})
+	}
+}
+
+func BenchmarkOneDestination(b *testing.B) {
+	benchmarkDestinations(b, AlsoWriter(io.Discard))
+}
+
+func BenchmarkTwoDestinationsSameFormat(b *testing.B) {
+	benchmarkDestinations(b, AlsoWriter(io.Discard), AlsoWriter(io.Discard))
+}
+
+func BenchmarkTwoDestinationsTwoFormats(b *testing.B) {
+	benchmarkDestinations(b, AlsoWriter(io.Discard), AlsoWriterFormat(io.Discard, JSONFormatter{}))
+}
+
+func BenchmarkJSONFormat(b *testing.B) {
+	r := &Record{Time: time.Now(), Level: INFO, Func: "main.main", File: "main.go", Line: 42, Message: "Wake up, Neo", Fields: []Field{{Key: "user", Value: "neo"}}}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		JSONFormatter{}.Format(r)
+	}
+}
+
+// lockCheckingFormatter formats records as text, reporting whether the
+// mutex of the logger was held while formatting.
+type lockCheckingFormatter struct {
+	locked *int32
+}
+
+func (f lockCheckingFormatter) Format(r *Record) []byte {
+	if loggerInstance.mu.TryLock() {
+		loggerInstance.mu.Unlock()
+	} else {
+		atomic.AddInt32(f.locked, 1)
+	}
+	return TextFormatter{}.Format(r)
+}
+
+func TestFormatOutsideLock(t *testing.T) {
+	var locked int32
+	buf := &Buffer{}
+	l := Start(WithFormatter(lockCheckingFormatter{&locked}), AlsoWriter(buf))
+	Infof("%s", "first")
+	Warnln("second")
+	l.Stop()
+
+	if locked != 0 {
+		t.Errorf("%d records formatted under the mutex of the logger", locked)
+	}
+	checkRecords(t, buf.String(), []string{"- first", "- second"})
+}
@@ -2,10 +2,11 @@ package holmes
 
 import (
 	"fmt"
-	"log"
 	"os"
-	"path"
+	"reflect"
 	"runtime"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 )
@@ -38,195 +39,533 @@ var (
 	}
 )
 
-// Start returns a decorated innerLogger.
+// String returns the name of the level, e.g. "INFO".
+func (level LogLevel) String() string {
+	if name, ok := tagName[level]; ok {
+		return name
+	}
+	return fmt.Sprintf("LogLevel(%d)", int(level))
+}
+
+// Start returns a decorated innerLogger. It panics if called again before
+// Stop, if the decorators conflict with each other or if the log file path
+// has less free space than required by MinFreeSpace.
 func Start(decorators ...func(Logger) Logger) Logger {
 	if atomic.CompareAndSwapInt32(&started, 0, 1) {
 		loggerInstance = Logger{}
 		for _, decorator := range decorators {
 			loggerInstance = decorator(loggerInstance)
 		}
-		var logger *log.Logger
+		if err := loggerInstance.check(); err != nil {
+			loggerInstance = Logger{}
+			atomic.StoreInt32(&started, 0)
+			panic(err)
+		}
+		if err := loggerInstance.checkFreeSpace(); err != nil {
+			loggerInstance = Logger{}
+			atomic.StoreInt32(&started, 0)
+			panic(err)
+		}
+		clock = loggerInstance.clock
+		if clock == nil {
+			clock = systemClock{}
+		}
+		if loggerInstance.ids != nil {
+			loggerInstance.ids.start()
+		}
+		if loggerInstance.jsonPretty {
+			loggerInstance.formatter = JSONFormatter{Indent: "  "}
+		}
+		if loggerInstance.formatter == nil {
+			loggerInstance.formatter = TextFormatter{}
+		}
+		loggerInstance.mu = &sync.Mutex{}
 		var segment *logSegment
 		if loggerInstance.logPath != "" {
-			segment = newLogSegment(loggerInstance.unit, loggerInstance.logPath)
+			segment = newLogSegment(loggerInstance)
 		}
+		var sinks []sink
 		if segment != nil {
-			logger = log.New(segment, "", log.LstdFlags)
+			sinks = append(sinks, sink{w: segment, level: loggerInstance.fileLevel, formatter: loggerInstance.fileFormatter})
+			if loggerInstance.isStdout {
+				sinks = append(sinks, sink{w: os.Stdout, level: loggerInstance.stdoutLevel, formatter: loggerInstance.stdoutFormatter})
+			}
 		} else if loggerInstance.isStdout {
-			logger = log.New(os.Stdout, "", log.LstdFlags)
+			// stdout stands in for the log file, so the settings of both apply
+			level, f := loggerInstance.fileLevel, loggerInstance.fileFormatter
+			if loggerInstance.stdoutLevel > level {
+				level = loggerInstance.stdoutLevel
+			}
+			if loggerInstance.stdoutFormatter != nil {
+				f = loggerInstance.stdoutFormatter
+			}
+			sinks = append(sinks, sink{w: os.Stdout, level: level, formatter: f})
 		} else {
-			logger = log.New(os.Stderr, "", log.LstdFlags)
+			sinks = append(sinks, sink{w: os.Stderr, level: loggerInstance.fileLevel, formatter: loggerInstance.fileFormatter})
+		}
+		loggerInstance.sinks = append(sinks, loggerInstance.writers...)
+		if loggerInstance.eventSource != "" {
+			if elog, err := openEventLog(loggerInstance.eventSource); err != nil {
+				internalError(err)
+			} else {
+				w := eventLogWriter{log: elog}
+				loggerInstance.sinks = append(loggerInstance.sinks, sink{w: w, level: DEBUG, closer: w})
+			}
+		}
+		loggerInstance.counts = &levelCounts{}
+		if loggerInstance.dropInterval == 0 {
+			loggerInstance.dropInterval = 30 * time.Second
+		}
+		loggerInstance.drops = &dropStats{interval: loggerInstance.dropInterval}
+		loggerInstance.segment = segment
+		for _, cw := range loggerInstance.channels {
+			cw.policy = loggerInstance.overflow
+		}
+		if loggerInstance.asyncSize > 0 {
+			loggerInstance.async = newAsyncWriter(loggerInstance.asyncSize, loggerInstance.ordering, loggerInstance.write, loggerInstance.counts)
+			loggerInstance.async.dropped = loggerInstance.dropped
+		}
+		if loggerInstance.tracePath != "" {
+			loggerInstance.trace = newTraceSampler(loggerInstance)
+		}
+		level := int32(loggerInstance.level)
+		loggerInstance.active = &level
+		if len(loggerInstance.schedule) > 0 {
+			loggerInstance.scheduler = newLevelScheduler(loggerInstance.schedule, loggerInstance.level, loggerInstance.active)
 		}
-		loggerInstance.logger = logger
 		return loggerInstance
 	}
 	panic("Start() already called")
 }
 
+// Clone returns a copy of the started logger changed by decorators, see
+// Logger.Clone.
+func Clone(decorators ...func(Logger) Logger) Logger {
+	return loggerInstance.Clone(decorators...)
+}
+
+// Clone returns a copy of l changed by decorators, sharing its outputs, e.g.
+// l.Clone(NoExitOnFatal, WarnLevel). Only the decorators changing how records
+// are logged have an effect, the outputs are set up by Start. A level set by
+// the decorators makes the clone independent of SetLevel. Only the logger
+// returned by Start must be stopped.
+func (l Logger) Clone(decorators ...func(Logger) Logger) Logger {
+	level, ids := l.level, l.ids
+	for _, decorator := range decorators {
+		l = decorator(l)
+	}
+	if l.ids != ids && l.ids != nil {
+		l.ids.start()
+	}
+	if l.level != level {
+		active := int32(l.level)
+		l.active = &active
+	}
+	return l
+}
+
 // Stop stops the logger.
 func (l Logger) Stop() {
 	if atomic.CompareAndSwapInt32(&l.stopped, 0, 1) {
+		if l.scheduler != nil {
+			l.scheduler.stop()
+		}
+		l.warnDropped(Block)
+		if l.summaryOnStop {
+			l.emit(&Record{Level: INFO, Message: l.counts.summary()})
+		}
 		if l.printStack {
 			traceInfo := make([]byte, 1<<16)
 			n := runtime.Stack(traceInfo, true)
-			l.logger.Printf("%s", traceInfo[:n])
-			if l.isStdout {
-				log.Printf("%s", traceInfo[:n])
-			}
+			l.emit(&Record{Level: INFO, Message: string(traceInfo[:n])})
+		}
+		if l.async != nil {
+			l.async.close()
 		}
 		if l.segment != nil {
 			l.segment.Close()
 		}
+		if l.trace != nil {
+			l.trace.segment.Close()
+		}
+		for _, s := range l.sinks {
+			if s.closer != nil {
+				s.closer.Close()
+			}
+		}
 		l.segment = nil
-		l.logger = nil
+		l.sinks = nil
+		loggerInstance = Logger{}
 		atomic.StoreInt32(&started, 0)
 	}
 }
 
-// logSegment implements io.Writer
-type logSegment struct {
-	unit         time.Duration
-	logPath      string
-	logFile      *os.File
-	timeToCreate <-chan time.Time
-}
-
-func newLogSegment(unit time.Duration, logPath string) *logSegment {
-	now := time.Now()
-	if logPath != "" {
-		err := os.MkdirAll(logPath, os.ModePerm)
-		if err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			return nil
-		}
-		name := getLogFileName(time.Now())
-		logFile, err := os.OpenFile(path.Join(logPath, name), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
-		if err != nil {
-			if os.IsNotExist(err) {
-				logFile, err = os.Create(path.Join(logPath, name))
-				if err != nil {
-					fmt.Fprintln(os.Stderr, err)
-					return nil
-				}
-			} else {
-				fmt.Fprintln(os.Stderr, err)
-				return nil
-			}
-		}
-		next := now.Truncate(unit).Add(unit)
-		var timeToCreate <-chan time.Time
-		if unit == time.Hour || unit == time.Minute {
-			timeToCreate = time.After(next.Sub(time.Now()))
-		}
-		return &logSegment{
-			unit:         unit,
-			logPath:      logPath,
-			logFile:      logFile,
-			timeToCreate: timeToCreate,
-		}
-	}
-	return nil
-}
-
-func (ls *logSegment) Write(p []byte) (n int, err error) {
-	if ls.timeToCreate != nil && ls.logFile != os.Stdout && ls.logFile != os.Stderr {
-		select {
-		case current := <-ls.timeToCreate:
-			ls.logFile.Close()
-			ls.logFile = nil
-			name := getLogFileName(current)
-			ls.logFile, err = os.Create(path.Join(ls.logPath, name))
-			if err != nil {
-				// log into stderr if we can't create new file
-				fmt.Fprintln(os.Stderr, err)
-				ls.logFile = os.Stderr
-			} else {
-				next := current.Truncate(ls.unit).Add(ls.unit)
-				ls.timeToCreate = time.After(next.Sub(time.Now()))
-			}
-		default:
-			// do nothing
-		}
+// Logger is the logger type.
+type Logger struct {
+	sinks           []sink
+	mu              *sync.Mutex
+	writers         []sink
+	eventSource     string
+	fileLevel       LogLevel
+	stdoutLevel     LogLevel
+	level           LogLevel
+	active          *int32
+	segment         *logSegment
+	stopped         int32
+	logPath         string
+	unit            time.Duration
+	isStdout        bool
+	printStack      bool
+	enrichers       []*enricher
+	counts          *levelCounts
+	summaryOnStop   bool
+	maxRecords      int64
+	minRotation     time.Duration
+	fileFilter      *fileFilter
+	formatter       Formatter
+	stackOnError    bool
+	stackFilter     stackFilter
+	stackForErrors  func(error) bool
+	dropInterval    time.Duration
+	drops           *dropStats
+	truncateOnStart bool
+	asyncSize       int
+	ordering        OrderingMode
+	overflow        OverflowPolicy
+	async           *asyncWriter
+	jsonPretty      bool
+	fileFormatter   Formatter
+	stdoutFormatter Formatter
+	baseFields      []Field
+	requestFields   []Field
+	configErr       error
+	minFreeSpace    int64
+	schemaHeader    bool
+	schedule        []ScheduleEntry
+	scheduler       *levelScheduler
+	traceRate       float64
+	tracePath       string
+	trace           *traceSampler
+	channels        []*channelWriter
+	componentLevels map[string]LogLevel
+	componentLevel  *LogLevel
+	callerPackage   bool
+	rePanic         bool
+	atomicFinalize  bool
+	noExit          bool
+	exitFunc        func(code int)
+	location        *time.Location
+	dedup           *callerDedup
+	maxFields       int
+	maxValueLength  int
+	failoverPath    string
+	onRotate        func(oldPath, newPath string)
+	flushModes      map[LogLevel]FlushMode
+	ids             *idGenerator
+	clock           Clock
+	idleClose       time.Duration
+	limiter         *rateLimiter
+	alwaysEmit      func(LogLevel, string) bool
+}
+
+// SetLevel changes the minimum level of the running logger, it is safe to call
+// concurrently with logging. A LevelSchedule overrides it at its next boundary.
+func SetLevel(level LogLevel) {
+	if loggerInstance.active != nil {
+		atomic.StoreInt32(loggerInstance.active, int32(level))
 	}
-	return ls.logFile.Write(p)
 }
 
-func (ls *logSegment) Close() {
-	ls.logFile.Close()
+// minLevel returns the minimum level of the records logged by l.
+func (l Logger) minLevel() LogLevel {
+	if l.active == nil {
+		return l.level
+	}
+	return LogLevel(atomic.LoadInt32(l.active))
 }
 
-func getLogFileName(t time.Time) string {
-	proc := path.Base(os.Args[0])
-	now := time.Now()
-	year := now.Year()
-	month := now.Month()
-	day := now.Day()
-	hour := now.Hour()
-	minute := now.Minute()
-	pid := os.Getpid()
-	return fmt.Sprintf("%s.%04d-%02d-%02d-%02d-%02d.%d.log",
-		proc, year, month, day, hour, minute, pid)
+// enabled reports whether l logs records of level.
+func (l Logger) enabled(level LogLevel) bool {
+	if l.componentLevel != nil {
+		return level >= *l.componentLevel
+	}
+	return level >= l.minLevel()
 }
 
-// Logger is the logger type.
-type Logger struct {
-	logger     *log.Logger
-	level      LogLevel
-	segment    *logSegment
-	stopped    int32
-	logPath    string
-	unit       time.Duration
-	isStdout   bool
-	printStack bool
+// sinksFor returns the sinks to write the next record of level to, none if
+// the record is not logged, and whether it is only written to the trace files.
+func (l Logger) sinksFor(level LogLevel) ([]sink, bool) {
+	if len(l.sinks) == 0 {
+		return nil, false
+	}
+	enabled := l.enabled(level)
+	if l.trace == nil || !l.trace.sample() {
+		if enabled {
+			return l.sinks, false
+		}
+		return nil, false
+	}
+	if !enabled {
+		return l.trace.sinks, true
+	}
+	return append(l.sinks[:len(l.sinks):len(l.sinks)], l.trace.sinks...), false
 }
 
 func (l Logger) doPrintf(level LogLevel, format string, v ...interface{}) {
-	if l.logger == nil {
-		return
-	}
-	if level >= l.level {
+	if sinks, traceOnly := l.sinksFor(level); len(sinks) > 0 {
 		funcName, fileName, lineNum := getRuntimeInfo()
-		format = fmt.Sprintf("%5s [%s] (%s:%d) - %s", tagName[level], path.Base(funcName), path.Base(fileName), lineNum, format)
-		l.logger.Printf(format, v...)
-		if l.isStdout {
-			log.Printf(format, v...)
+		if !l.fileFilter.allows(fileName) {
+			return
 		}
-		if level == FATAL {
-			os.Exit(1)
+		r := &Record{Level: level, Func: funcName, File: fileName, Line: lineNum, traceOnly: traceOnly}
+		render := func() {
+			v, r.Fields = splitFields(v)
+			r.Message = strings.TrimSuffix(fmt.Sprintf(format, v...), "\n")
+		}
+		if l.admit(r, render) {
+			l.emitTo(r, sinks)
 		}
 	}
 }
 
 func (l Logger) doPrintln(level LogLevel, v ...interface{}) {
-	if l.logger == nil {
-		return
-	}
-	if level >= l.level {
+	if sinks, traceOnly := l.sinksFor(level); len(sinks) > 0 {
 		funcName, fileName, lineNum := getRuntimeInfo()
-		prefix := fmt.Sprintf("%5s [%s] (%s:%d) - ", tagName[level], path.Base(funcName), path.Base(fileName), lineNum)
-		value := fmt.Sprintf("%s%s", prefix, fmt.Sprintln(v...))
-		l.logger.Print(value)
-		if l.isStdout {
-			log.Print(value)
+		if !l.fileFilter.allows(fileName) {
+			return
 		}
-		if level == FATAL {
-			os.Exit(1)
+		r := &Record{Level: level, Func: funcName, File: fileName, Line: lineNum, traceOnly: traceOnly}
+		render := func() {
+			v, r.Fields = splitFields(v)
+			r.Message = strings.TrimSuffix(fmt.Sprintln(v...), "\n")
+		}
+		if l.admit(r, render) {
+			l.emitTo(r, sinks)
 		}
 	}
 }
 
-func getRuntimeInfo() (string, string, int) {
-	pc, fn, ln, ok := runtime.Caller(3) // 3 steps up the stack frame
+// admit reports whether the record r of a log call passes DedupByCaller and
+// RateLimit, rendering its message and fields with render once it does, or
+// beforehand for the AlwaysEmit predicate which bypasses them. Records only
+// written to the trace files always pass.
+func (l Logger) admit(r *Record, render func()) bool {
+	if r.traceOnly {
+		render()
+		return true
+	}
+	if l.alwaysEmit != nil {
+		render()
+		if l.alwaysEmit(r.Level, r.Message) {
+			r.always = true
+			return true
+		}
+	}
+	ok, repeated := l.dedup.allow(r.File, r.Line)
 	if !ok {
-		fn = "???"
-		ln = 0
+		return false
+	}
+	if !l.limiter.allow() {
+		l.dropped()
+		return false
 	}
-	function := "???"
-	caller := runtime.FuncForPC(pc)
-	if caller != nil {
-		function = caller.Name()
+	if l.alwaysEmit == nil {
+		render()
 	}
-	return function, fn, ln
+	if repeated > 0 {
+		r.Fields = append(r.Fields, Field{Key: "repeated", Value: repeated})
+	}
+	return true
+}
+
+// emit writes r to the sinks of l, see emitTo.
+func (l Logger) emit(r *Record) {
+	l.emitTo(r, l.sinks)
+}
+
+// emitTo writes r to sinks, in the background if logging asynchronously,
+// counting the records dropped when the Async buffer overflows but those only
+// written to the trace files. Records of AlwaysEmit wait for room in the
+// buffer instead.
+func (l Logger) emitTo(r *Record, sinks []sink) {
+	if l.wantsStack(r) {
+		frames := callerFrames(3) // emitTo, doPrintf and Log or another log function
+		if delegatingFuncs[frames[0].Function] {
+			frames = frames[1:] // Errorf
+		}
+		r.Stack = l.stackFilter.apply(frames)
+	}
+	if l.callerPackage && r.File != "" {
+		r.Fields = append([]Field{{Key: "pkg", Value: packageOf(r.Func)}}, r.Fields...)
+	}
+	policy := l.overflow
+	if r.always {
+		policy = Block
+	}
+	queued, dropped := l.send(r, sinks, policy)
+	for ; dropped > 0; dropped-- {
+		l.dropped()
+	}
+	if !queued && !r.traceOnly {
+		l.dropped()
+	}
+}
+
+// send completes r and writes it to sinks, queuing it according to policy if logging
+// asynchronously. It reports whether r was written or queued and the number
+// of records evicted to make room for it, or of deliveries of r dropped by
+// Channel sinks.
+func (l Logger) send(r *Record, sinks []sink, policy OverflowPolicy) (bool, int) {
+	r.Time = timeNow()
+	if l.location != nil {
+		r.Time = r.Time.In(l.location)
+	}
+	r.Fields = l.fields(r.Fields)
+	if l.ids != nil && !r.traceOnly {
+		r.Fields = append(r.Fields, Field{Key: "id", Value: l.ids.next()})
+	}
+	if l.async == nil {
+		written, dropped := l.write(r, sinks)
+		if written && !r.traceOnly {
+			l.counts.inc(r.Level)
+		}
+		return true, dropped
+	}
+	if !r.traceOnly {
+		l.counts.inc(r.Level) // uncounted if dropped from the buffer or by the sinks
+	}
+	queued, evicted := l.async.push(r, sinks, policy)
+	if !queued && !r.traceOnly {
+		l.counts.dec(r.Level)
+	}
+	if r.Level >= FATAL {
+		l.async.flush() // the process exits right after
+	}
+	return queued, evicted
+}
+
+// write formats r once per distinct formatter and writes it to all sinks
+// accepting its level. Only the writes are serialized, so that Relaxed
+// formats in parallel, and records are delivered to Channel sinks after the
+// others, unlocked, so that a full channel does not hold up the logger. It
+// reports false if all the sinks accepting r dropped it, and the number of
+// Channel sinks which dropped r, not counting drop warnings.
+func (l Logger) write(r *Record, sinks []sink) (bool, int) {
+	var formatted formattedLines
+	var buf [8][]byte
+	lines := buf[:0]
+	accepting := 0
+	for _, s := range sinks {
+		var line []byte
+		if r.Level >= s.level {
+			f := s.formatter
+			if f == nil {
+				f = l.formatter
+			}
+			line = formatted.format(f, r)
+			accepting++
+		}
+		lines = append(lines, line)
+	}
+	dropped, channelDrops := 0, 0
+	l.mu.Lock()
+	for i, s := range sinks {
+		if _, ok := s.w.(*channelWriter); !ok && r.Level >= s.level && s.write(r, lines[i]) == errDropped {
+			dropped++
+		}
+	}
+	l.mu.Unlock()
+	for i, s := range sinks {
+		if _, ok := s.w.(*channelWriter); ok && r.Level >= s.level && s.write(r, lines[i]) == errDropped {
+			dropped++
+			if r.drops == 0 {
+				channelDrops++
+			}
+		}
+	}
+	return accepting == 0 || dropped < accepting, channelDrops
+}
+
+// internalError reports an error raised inside the logger itself.
+func internalError(err error) {
+	fmt.Fprintln(os.Stderr, err)
+}
+
+// callerInfo is the function, file and line of a call site.
+type callerInfo struct {
+	function   string
+	file       string
+	line       int
+	delegating bool // a named function delegating to Log or Logln
+}
+
+// callerCache maps program counters to their callerInfo. It needs no bound
+// as a binary holds a finite number of call sites.
+var callerCache sync.Map
+
+// getRuntimeInfo returns the call site of the log call, skipping the frame
+// of a named function such as Infof delegating to Log or Logln.
+func getRuntimeInfo() (string, string, int) {
+	var pcs [2]uintptr
+	n := runtime.Callers(4, pcs[:]) // Callers, getRuntimeInfo, doPrintf and Log
+	if n == 0 {
+		return "???", "???", 0
+	}
+	info := callerAt(pcs[0])
+	if info.delegating && n > 1 {
+		info = callerAt(pcs[1])
+	}
+	return info.function, info.file, info.line
+}
+
+// delegatingFuncs are the named log functions delegating to Log or Logln.
+var delegatingFuncs = map[string]bool{}
+
+func init() {
+	for _, name := range []string{"Debugf", "Infof", "Warnf", "Errorf", "Fatalf", "Debugln", "Infoln", "Warnln", "Errorln", "Fatalln"} {
+		delegatingFuncs[holmesPackage+"."+name] = true
+		delegatingFuncs[holmesPackage+".Logger."+name] = true
+	}
+}
+
+// holmesPackage is the import path of holmes, e.g. github.com/leesper/holmes.
+var holmesPackage = packageOf(runtime.FuncForPC(reflect.ValueOf(Log).Pointer()).Name())
+
+// callerAt returns the callerInfo of the call site at pc.
+func callerAt(pc uintptr) callerInfo {
+	if info, ok := callerCache.Load(pc); ok {
+		return info.(callerInfo)
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	info := callerInfo{function: frame.Function, file: frame.File, line: frame.Line, delegating: delegatingFuncs[frame.Function]}
+	if info.function == "" {
+		info.function = "???"
+	}
+	if info.file == "" {
+		info.file = "???"
+	}
+	callerCache.Store(pc, info)
+	return info
+}
+
+// packageOf returns the import path of the package of a function name
+// reported by the runtime, e.g. github.com/leesper/holmes for
+// github.com/leesper/holmes.(*Logger).Infof.
+func packageOf(funcName string) string {
+	slash := strings.LastIndex(funcName, "/")
+	dot := strings.Index(funcName[slash+1:], ".")
+	if dot < 0 {
+		return ""
+	}
+	// dots in the last element of the path are escaped, e.g. gopkg.in/yaml%2ev2
+	return strings.Replace(funcName[:slash+1+dot], "%2e", ".", -1)
+}
+
+// CallerPackage sets the records have a pkg field holding the import path
+// of the package of their caller.
+func CallerPackage(l Logger) Logger {
+	l.callerPackage = true
+	return l
 }
 
 // DebugLevel sets log level to debug.
@@ -259,7 +598,8 @@ func FatalLevel(l Logger) Logger {
 	return l
 }
 
-// LogFilePath returns a function to set the log file path.
+// LogFilePath returns a function to set the log file path. If a log file of
+// the same name already exists it is appended to, see TruncateOnStart.
 func LogFilePath(p string) func(Logger) Logger {
 	return func(l Logger) Logger {
 		l.logPath = p
@@ -267,6 +607,16 @@ func LogFilePath(p string) func(Logger) Logger {
 	}
 }
 
+// Location returns a function to render the log file names and the record
+// timestamps in loc instead of the local time zone, rotating log files on
+// the clock of loc, e.g. Location(time.UTC).
+func Location(loc *time.Location) func(Logger) Logger {
+	return func(l Logger) Logger {
+		l.location = loc
+		return l
+	}
+}
+
 // EveryDay sets new log file created every day.
 func EveryDay(l Logger) Logger {
 	l.unit = time.Hour * 24
@@ -285,6 +635,74 @@ func EveryMinute(l Logger) Logger {
 	return l
 }
 
+// MinFreeSpace returns a function to make Start fail if the filesystem of the
+// log file path has less than bytes of free space.
+func MinFreeSpace(bytes int64) func(Logger) Logger {
+	return func(l Logger) Logger {
+		l.minFreeSpace = bytes
+		return l
+	}
+}
+
+// TruncateOnStart sets the log file opened by Start truncated instead of
+// appended to. Log files are named after the PID, so only a restarted process
+// reusing the PID within the same minute truncates the file of a previous run.
+func TruncateOnStart(l Logger) Logger {
+	l.truncateOnStart = true
+	return l
+}
+
+// MaxRecords returns a function to set new log file created after n records written.
+func MaxRecords(n int) func(Logger) Logger {
+	return func(l Logger) Logger {
+		l.maxRecords = int64(n)
+		return l
+	}
+}
+
+// AtomicFinalize sets log write every log file under a temporary name with a
+// .tmp suffix, renamed to its final name once the file is rotated or the
+// logger stopped, so that consumers of the final names only see complete files.
+func AtomicFinalize(l Logger) Logger {
+	l.atomicFinalize = true
+	return l
+}
+
+// Rotate closes the current log file and continues logging into a new one.
+func Rotate() {
+	l := loggerInstance
+	if l.segment == nil {
+		return
+	}
+	if l.async != nil {
+		l.async.flush()
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.segment.rotate(timeNow().In(l.segment.loc))
+}
+
+// OnRotate returns a function to call fn with the names of the closed and of
+// the new log file every time the log file is rotated, e.g. to ship the closed
+// one. With AtomicFinalize, the names are the final ones. The new name is empty
+// if the new log file could not be created. fn runs in its own goroutine.
+func OnRotate(fn func(oldPath, newPath string)) func(Logger) Logger {
+	return func(l Logger) Logger {
+		l.onRotate = fn
+		return l
+	}
+}
+
+// MinRotationInterval returns a function to skip the rotations by MaxRecords
+// happening less than d after the previous rotation, letting the log file grow
+// past the limit for a while instead of creating many small files in a burst.
+func MinRotationInterval(d time.Duration) func(Logger) Logger {
+	return func(l Logger) Logger {
+		l.minRotation = d
+		return l
+	}
+}
+
 // AlsoStdout sets log also output to stdio.
 func AlsoStdout(l Logger) Logger {
 	l.isStdout = true
@@ -297,54 +715,109 @@ func PrintStack(l Logger) Logger {
 	return l
 }
 
+// SummaryOnStop sets log output the record counts per level when stopped.
+func SummaryOnStop(l Logger) Logger {
+	l.summaryOnStop = true
+	return l
+}
+
+// NoExitOnFatal sets the fatal records are logged without exiting, e.g. for
+// a Clone used by a plugin which must not stop the process.
+func NoExitOnFatal(l Logger) Logger {
+	l.noExit = true
+	return l
+}
+
+// ExitFunc returns a function to make fatal records exit by calling fn with
+// the exit code instead of os.Exit, e.g. to run cleanups or in tests.
+func ExitFunc(fn func(code int)) func(Logger) Logger {
+	return func(l Logger) Logger {
+		l.exitFunc = fn
+		return l
+	}
+}
+
+// exit ends the process after a fatal record unless NoExitOnFatal is set.
+func (l Logger) exit() {
+	if l.noExit {
+		return
+	}
+	if l.exitFunc != nil {
+		l.exitFunc(1)
+		return
+	}
+	os.Exit(1)
+}
+
+// Log prints formatted log at level, it exits if level is FATAL, see NoExitOnFatal.
+// Levels outside DEBUG to FATAL are tagged like LogLevel(5), and not counted
+// by LevelCounts.
+func Log(level LogLevel, format string, v ...interface{}) {
+	loggerInstance.doPrintf(level, format, v...)
+	if level >= FATAL {
+		loggerInstance.exit()
+	}
+}
+
+// Logln prints log at level, it exits if level is FATAL, see NoExitOnFatal.
+func Logln(level LogLevel, v ...interface{}) {
+	loggerInstance.doPrintln(level, v...)
+	if level >= FATAL {
+		loggerInstance.exit()
+	}
+}
+
 // Debugf prints formatted debug log.
 func Debugf(format string, v ...interface{}) {
-	loggerInstance.doPrintf(DEBUG, format, v...)
+	Log(DEBUG, format, v...)
 }
 
 // Infof prints formatted info log.
 func Infof(format string, v ...interface{}) {
-	loggerInstance.doPrintf(INFO, format, v...)
+	Log(INFO, format, v...)
 }
 
 // Warnf prints formatted warn log.
 func Warnf(format string, v ...interface{}) {
-	loggerInstance.doPrintf(WARN, format, v...)
+	Log(WARN, format, v...)
 }
 
 // Errorf prints formatted error log.
 func Errorf(format string, v ...interface{}) {
-	loggerInstance.doPrintf(ERROR, format, v...)
+	Log(ERROR, format, v...)
+}
+
+// ErrorErr prints error log with err attached as the error field.
+func ErrorErr(err error, v ...interface{}) {
+	loggerInstance.doPrintln(ERROR, append(v[:len(v):len(v)], Field{Key: "error", Value: err})...)
 }
 
 // Fatalf prints formatted fatal log and exits.
 func Fatalf(format string, v ...interface{}) {
-	loggerInstance.doPrintf(FATAL, format, v...)
-	os.Exit(1)
+	Log(FATAL, format, v...)
 }
 
 // Debugln prints debug log.
 func Debugln(v ...interface{}) {
-	loggerInstance.doPrintln(DEBUG, v...)
+	Logln(DEBUG, v...)
 }
 
 // Infoln prints info log.
 func Infoln(v ...interface{}) {
-	loggerInstance.doPrintln(INFO, v...)
+	Logln(INFO, v...)
 }
 
 // Warnln prints warn log.
 func Warnln(v ...interface{}) {
-	loggerInstance.doPrintln(WARN, v...)
+	Logln(WARN, v...)
 }
 
 // Errorln prints error log.
 func Errorln(v ...interface{}) {
-	loggerInstance.doPrintln(ERROR, v...)
+	Logln(ERROR, v...)
 }
 
 // Fatalln prints fatal log and exits.
 func Fatalln(v ...interface{}) {
-	loggerInstance.doPrintln(FATAL, v...)
-	os.Exit(1)
+	Logln(FATAL, v...)
 }
@@ -1,6 +1,14 @@
 package holmes
 
 import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
 	"sync"
 	"testing"
 )
@@ -88,3 +96,215 @@ func BenchmarkFileLoggerMultipleGoroutine(b *testing.B) {
 	}
 	wg.Wait()
 }
+
+// readLogs returns the content of all log files under dir.
+func readLogs(t *testing.T, dir string) string {
+	files, err := filepath.Glob(filepath.Join(dir, "*.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var content string
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		content += string(data)
+	}
+	return content
+}
+
+// logDebug logs msg from a file other than the calling test.
+func logDebug(msg string) {
+	Debugln(msg)
+}
+
+func TestLog(t *testing.T) {
+	buf := &Buffer{}
+	l := Start(JSONFormat, AlsoWriter(buf))
+	_, _, line, _ := runtime.Caller(0)
+	Log(WARN, "%s", "dynamic level")
+	Warnf("%s", "dynamic level")
+	Logln(ERROR, "dynamic", "level")
+	Errorln("dynamic", "level")
+	FromContext(context.Background()).Log(INFO, "%s", "dynamic level")
+	FromContext(context.Background()).Infof("%s", "dynamic level")
+	FromContext(context.Background()).Logln(DEBUG, "dynamic", "level")
+	FromContext(context.Background()).Debugln("dynamic", "level")
+	l.Stop()
+
+	var records []map[string]interface{}
+	for i, text := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(text), &record); err != nil {
+			t.Fatal(err)
+		}
+		if record["file"] != "holmes_test.go" || record["line"] != float64(line+1+i) {
+			t.Errorf("record %d: got %v:%v, want holmes_test.go:%d", i, record["file"], record["line"], line+1+i)
+		}
+		delete(record, "time")
+		delete(record, "line")
+		records = append(records, record)
+	}
+	if len(records) != 8 {
+		t.Fatalf("got %d records, want 8", len(records))
+	}
+	for i := 0; i < len(records); i += 2 {
+		if !reflect.DeepEqual(records[i], records[i+1]) {
+			t.Errorf("got %v, want %v", records[i], records[i+1])
+		}
+	}
+}
+
+func TestLogUnknownLevels(t *testing.T) {
+	buf := &Buffer{}
+	l := Start(AlsoWriter(buf), NoExitOnFatal)
+	Log(LogLevel(5), "%s", "above fatal")
+	Logln(LogLevel(-1), "below debug")
+	counts := LevelCounts()
+	l.Stop()
+
+	out := buf.String()
+	if !strings.Contains(out, "LogLevel(5) [holmes.TestLogUnknownLevels]") || !strings.Contains(out, "above fatal") {
+		t.Errorf("got %q, want a LogLevel(5) record", out)
+	}
+	if strings.Contains(out, "below debug") {
+		t.Errorf("got %q, want no record below the debug level", out)
+	}
+	for level, n := range counts {
+		if n != 0 {
+			t.Errorf("got %d records counted at %s, want 0", n, level)
+		}
+	}
+}
+
+// uncachedRuntimeInfo resolves the caller like getRuntimeInfo did before
+// caching, for comparison.
+func uncachedRuntimeInfo() (string, string, int) {
+	pc, fn, ln, _ := runtime.Caller(3)
+	return runtime.FuncForPC(pc).Name(), fn, ln
+}
+
+// resolveCaller returns the cached and uncached caller of its caller.
+func resolveCaller() (cached, uncached callerInfo) {
+	return func() (cached, uncached callerInfo) {
+		cached.function, cached.file, cached.line = getRuntimeInfo()
+		uncached.function, uncached.file, uncached.line = uncachedRuntimeInfo()
+		return cached, uncached
+	}()
+}
+
+func TestCallerCache(t *testing.T) {
+	var lines []int
+	for i := 0; i < 3; i++ { // resolve every call site several times to hit the cache
+		for _, resolve := range []func() (callerInfo, callerInfo){
+			func() (callerInfo, callerInfo) { return resolveCaller() },
+			func() (callerInfo, callerInfo) { return resolveCaller() },
+		} {
+			cached, uncached := resolve()
+			if cached != uncached {
+				t.Errorf("got cached caller %+v, want %+v", cached, uncached)
+			}
+			lines = append(lines, cached.line)
+		}
+	}
+	if lines[0] == lines[1] || lines[0] != lines[2] {
+		t.Errorf("got caller lines %v", lines)
+	}
+}
+
+func TestPackageOf(t *testing.T) {
+	tests := map[string]string{
+		"main.main":                                          "main",
+		"main.(*server).serve.func1":                         "main",
+		"github.com/leesper/holmes.Infof":                    "github.com/leesper/holmes",
+		"github.com/leesper/holmes.(*Logger).Infof":          "github.com/leesper/holmes",
+		"github.com/leesper/holmes.TestLog.func2.1":          "github.com/leesper/holmes",
+		"net/http.HandlerFunc.ServeHTTP":                     "net/http",
+		"gopkg.in/yaml%2ev2.Marshal":                         "gopkg.in/yaml.v2",
+		"example.com/cache.(*LRU[...]).Get":                  "example.com/cache",
+		"example.com/x/v2.Map[go.shape.int,go.shape.string]": "example.com/x/v2",
+		"???": "",
+	}
+	for name, want := range tests {
+		if got := packageOf(name); got != want {
+			t.Errorf("packageOf(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestCallerPackage(t *testing.T) {
+	buf := &Buffer{}
+	l := Start(JSONFormat, CallerPackage, AlsoWriter(buf))
+	Infof("%s", "faceted")
+	l.Stop()
+
+	if !strings.Contains(buf.String(), `"pkg":"github.com/leesper/holmes"`) {
+		t.Errorf("got %q, want the pkg field", buf.String())
+	}
+}
+
+func BenchmarkRuntimeInfoCached(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		resolveCached()
+	}
+}
+
+func BenchmarkRuntimeInfoUncached(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		resolveUncached()
+	}
+}
+
+func resolveCached() {
+	func() { getRuntimeInfo() }()
+}
+
+func resolveUncached() {
+	func() { uncachedRuntimeInfo() }()
+}
+
+func BenchmarkLoggingLoop(b *testing.B) {
+	defer Start(AlsoWriter(io.Discard), FileLevel(FATAL)).Stop()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Infof("%s", "Wake up, Neo")
+	}
+}
+
+func TestNoExitOnFatal(t *testing.T) {
+	buf := &Buffer{}
+	var codes []int
+	l := Start(AlsoWriter(buf), ExitFunc(func(code int) { codes = append(codes, code) }))
+	plugin := Clone(NoExitOnFatal)
+	plugin.Fatalf("%s", "plugin failed")
+	plugin.Fatalln("plugin failed again")
+	plugin.Log(FATAL, "%s", "and again")
+	if len(codes) != 0 {
+		t.Errorf("sandboxed logger exited with %v", codes)
+	}
+	Fatalln("main failed")
+	l.Stop()
+
+	if len(codes) != 1 || codes[0] != 1 {
+		t.Errorf("got exit codes %v, want [1]", codes)
+	}
+	if n := strings.Count(buf.String(), "FATAL"); n != 4 {
+		t.Errorf("got %d fatal records, want 4", n)
+	}
+}
+
+func TestClone(t *testing.T) {
+	buf := &Buffer{}
+	l := Start(InfoLevel, AlsoWriter(buf))
+	quiet := Clone(ErrorLevel)
+	quiet.Warnf("%s", "hidden")
+	SetLevel(WARN)
+	Infof("%s", "hidden too")
+	Warnf("%s", "shown")
+	l.Stop()
+
+	if got := buf.String(); strings.Contains(got, "hidden") || !strings.Contains(got, "shown") {
+		t.Errorf("got %q", got)
+	}
+}
@@ -0,0 +1,99 @@
+// Package holmestest provides a sink capturing the records logged by holmes
+// and assertions on them, for testing code that logs.
+package holmestest
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/leesper/holmes"
+)
+
+// TB is the subset of testing.TB used by the assertions.
+type TB interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// TestSink captures the records written to it, it is safe for concurrent use.
+type TestSink struct {
+	mu      sync.Mutex
+	records []holmes.Record
+}
+
+// New returns an empty TestSink.
+func New() *TestSink {
+	return &TestSink{}
+}
+
+// Decorator returns a function to make log also output to s.
+func (s *TestSink) Decorator() func(holmes.Logger) holmes.Logger {
+	return holmes.AlsoWriter(s)
+}
+
+// Write implements io.Writer, the records are captured by WriteRecord.
+func (s *TestSink) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+// WriteRecord implements holmes.RecordWriter.
+func (s *TestSink) WriteRecord(r *holmes.Record, line []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, *r)
+	return nil
+}
+
+// Records returns a copy of the captured records.
+func (s *TestSink) Records() []holmes.Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]holmes.Record(nil), s.records...)
+}
+
+// Reset discards the captured records.
+func (s *TestSink) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = nil
+}
+
+// AssertLogged checks that a record at level containing substring in its
+// message was captured.
+func (s *TestSink) AssertLogged(t TB, level holmes.LogLevel, substring string) {
+	t.Helper()
+	for _, r := range s.Records() {
+		if r.Level == level && strings.Contains(r.Message, substring) {
+			return
+		}
+	}
+	t.Errorf("no %s record containing %q logged", level, substring)
+}
+
+// AssertCount checks that n records at level were captured.
+func (s *TestSink) AssertCount(t TB, level holmes.LogLevel, n int) {
+	t.Helper()
+	count := 0
+	for _, r := range s.Records() {
+		if r.Level == level {
+			count++
+		}
+	}
+	if count != n {
+		t.Errorf("got %d %s records, want %d", count, level, n)
+	}
+}
+
+// AssertField checks that a record carrying the field key with value was captured.
+func (s *TestSink) AssertField(t TB, key string, value interface{}) {
+	t.Helper()
+	for _, r := range s.Records() {
+		for _, f := range r.Fields {
+			if f.Key == key && reflect.DeepEqual(f.Value, value) {
+				return
+			}
+		}
+	}
+	t.Errorf("no record with field %s=%v logged", key, value)
+}
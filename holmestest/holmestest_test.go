@@ -0,0 +1,83 @@
+package holmestest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/leesper/holmes"
+)
+
+// recorder records the failures reported by an assertion.
+type recorder struct {
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func TestAssertLogged(t *testing.T) {
+	sink := New()
+	l := holmes.Start(sink.Decorator())
+	holmes.Warnf("disk %d%% full", 93)
+	l.Stop()
+
+	sink.AssertLogged(t, holmes.WARN, "93% full")
+	rec := &recorder{}
+	sink.AssertLogged(rec, holmes.ERROR, "93% full")
+	sink.AssertLogged(rec, holmes.WARN, "empty")
+	if len(rec.failures) != 2 {
+		t.Errorf("got failures %q, want 2", rec.failures)
+	}
+}
+
+func TestAssertCount(t *testing.T) {
+	sink := New()
+	l := holmes.Start(sink.Decorator())
+	wg := sync.WaitGroup{}
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			holmes.Infoln("concurrent")
+			holmes.Errorln("concurrent")
+			wg.Done()
+		}()
+	}
+	wg.Wait()
+	l.Stop()
+
+	sink.AssertCount(t, holmes.INFO, 50)
+	sink.AssertCount(t, holmes.ERROR, 50)
+	sink.AssertCount(t, holmes.DEBUG, 0)
+	rec := &recorder{}
+	sink.AssertCount(rec, holmes.INFO, 49)
+	if len(rec.failures) != 1 {
+		t.Errorf("got failures %q, want 1", rec.failures)
+	}
+}
+
+func TestAssertField(t *testing.T) {
+	sink := New()
+	l := holmes.Start(sink.Decorator(), holmes.BaseFields("service", "auth"))
+	ctx := holmes.WithFields(context.Background(), "user", 42)
+	holmes.FromContext(ctx).Infof("%s", "logged in")
+	l.Stop()
+
+	sink.AssertField(t, "service", "auth")
+	sink.AssertField(t, "user", 42)
+	rec := &recorder{}
+	sink.AssertField(rec, "user", "42")
+	sink.AssertField(rec, "missing", 42)
+	if len(rec.failures) != 2 {
+		t.Errorf("got failures %q, want 2", rec.failures)
+	}
+
+	sink.Reset()
+	if n := len(sink.Records()); n != 0 {
+		t.Errorf("got %d records after reset", n)
+	}
+}
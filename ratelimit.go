@@ -0,0 +1,60 @@
+package holmes
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RateLimit returns a function to log at most n records of the log functions
+// and of Writer per interval, e.g. RateLimit(100, time.Second) to protect the
+// disk from a runaway loop. The records over the limit are dropped like the
+// records of a full Async buffer, counting them in the drop warnings. n and
+// interval must be positive.
+func RateLimit(n int, interval time.Duration) func(Logger) Logger {
+	return func(l Logger) Logger {
+		if n <= 0 || interval <= 0 {
+			l.configErr = fmt.Errorf("holmes: RateLimit: %d records per %s, want positive values", n, interval)
+		}
+		l.limiter = &rateLimiter{n: n, interval: interval}
+		return l
+	}
+}
+
+// AlwaysEmit returns a function to log the records matching pred whatever
+// DedupByCaller, RateLimit or the Overflow policy of Async and Channel would drop, e.g.
+// the records of a request under investigation. pred is called for every
+// record of an enabled level, whose message is then always rendered.
+func AlwaysEmit(pred func(level LogLevel, msg string) bool) func(Logger) Logger {
+	return func(l Logger) Logger {
+		l.alwaysEmit = pred
+		return l
+	}
+}
+
+// rateLimiter counts the records logged in the current interval.
+type rateLimiter struct {
+	n        int
+	interval time.Duration
+	mu       sync.Mutex
+	start    time.Time
+	count    int
+}
+
+// allow reports whether to log a record in the current interval.
+func (rl *rateLimiter) allow() bool {
+	if rl == nil {
+		return true
+	}
+	now := timeNow()
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if now.Sub(rl.start) >= rl.interval {
+		rl.start, rl.count = now, 0
+	}
+	if rl.count >= rl.n {
+		return false
+	}
+	rl.count++
+	return true
+}
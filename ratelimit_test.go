@@ -0,0 +1,111 @@
+package holmes
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	clk := newTestClock(time.Date(2016, 7, 8, 11, 25, 0, 0, time.Local))
+	buf := &Buffer{}
+	l := Start(WithClock(clk), AlsoWriter(buf), RateLimit(2, time.Minute))
+	for i := 1; i <= 5; i++ {
+		Infof("%d", i)
+	}
+	clk.add(time.Minute)
+	Infoln(6)
+	l.Stop()
+
+	checkRecords(t, buf.String(), []string{
+		"- 1",
+		"- 2",
+		"WARN dropped 1 records dropped=1",
+		"- 6",
+		"WARN dropped 2 records dropped=2",
+	})
+}
+
+func TestRateLimitInvalid(t *testing.T) {
+	for _, limit := range []func(Logger) Logger{RateLimit(0, time.Second), RateLimit(10, 0)} {
+		func() {
+			defer func() {
+				err, _ := recover().(error)
+				if err == nil || !strings.Contains(err.Error(), "holmes: RateLimit:") {
+					t.Errorf("got %v, want a RateLimit error", err)
+				}
+			}()
+			Start(limit).Stop()
+		}()
+	}
+	Start().Stop() // a rejected Start can be retried
+}
+
+func TestAlwaysEmit(t *testing.T) {
+	clk := newTestClock(time.Date(2016, 7, 8, 11, 25, 0, 0, time.Local))
+	buf := &Buffer{}
+	incident := func(level LogLevel, msg string) bool {
+		return level >= ERROR || strings.Contains(msg, "request=42")
+	}
+	l := Start(WithClock(clk), AlsoWriter(buf), RateLimit(1, time.Minute), DedupByCaller(time.Minute), AlwaysEmit(incident))
+	handle := func(request int) {
+		Infof("handling request=%d", request)
+	}
+	for request := 40; request <= 43; request++ {
+		handle(request)
+	}
+	Errorln("disk full")
+	Warnln("unrelated")
+	l.Stop()
+
+	checkRecords(t, buf.String(), []string{
+		"- handling request=40",
+		"- handling request=42",
+		"- disk full",
+		"WARN dropped 1 records dropped=1",
+	})
+}
+
+func TestRateLimitWriter(t *testing.T) {
+	buf := &Buffer{}
+	l := Start(AlsoWriter(buf), RateLimit(1, time.Hour), AlwaysEmit(func(level LogLevel, msg string) bool {
+		return strings.Contains(msg, "urgent")
+	}))
+	w := Writer(INFO)
+	for i := 0; i < 5; i++ {
+		fmt.Fprintf(w, "write %d\n", i)
+	}
+	fmt.Fprintln(w, "urgent write")
+	l.Stop()
+
+	checkRecords(t, buf.String(), []string{
+		"- write 0",
+		"WARN dropped 1 records dropped=1",
+		"- urgent write",
+		"WARN dropped 3 records dropped=3",
+	})
+}
+
+func TestAlwaysEmitChannel(t *testing.T) {
+	ch := make(chan Record, 1)
+	l := Start(Channel(ch), Overflow(Drop), FileLevel(FATAL), AlwaysEmit(func(level LogLevel, msg string) bool {
+		return level >= ERROR
+	}))
+	Infof("%s", "first")
+	Infof("%s", "dropped")
+	logged := make(chan struct{})
+	go func() {
+		Errorf("%s", "urgent")
+		close(logged)
+	}()
+	if r := <-ch; r.Message != "first" {
+		t.Errorf("got record %q, want the first one", r.Message)
+	}
+	<-logged
+	l.Stop()
+
+	if r := <-ch; r.Message != "urgent" {
+		t.Errorf("got record %q, want the urgent one", r.Message)
+	}
+}
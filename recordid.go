@@ -0,0 +1,76 @@
+package holmes
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"sync/atomic"
+)
+
+// IDScheme selects how RecordID generates the IDs of records.
+type IDScheme int
+
+const (
+	// RandomID generates a random version 4 UUID per record, e.g.
+	// 3f2b9c1e-8d4a-4f6b-9a0e-5c7d1b2e4f60, reading the system random source
+	// for every record.
+	RandomID IDScheme = iota
+	// SequentialID generates a random prefix once and a counter per record,
+	// starting at the Unix time of Start in nanoseconds, e.g.
+	// 9c41d2e07a3b5f18145f333eb7295801 for the first record of a logger
+	// started at 2016-07-08 11:25:48 +08:00. It is several times cheaper than
+	// RandomID and unique across processes with a very high probability.
+	SequentialID
+)
+
+// RecordID returns a function to attach an id field holding an ID unique to
+// every record, e.g. to reference a record in an incident ticket.
+func RecordID(scheme IDScheme) func(Logger) Logger {
+	return func(l Logger) Logger {
+		l.ids = newIDGenerator(scheme)
+		return l
+	}
+}
+
+// idGenerator generates the IDs of records.
+type idGenerator struct {
+	scheme  IDScheme
+	prefix  [8]byte
+	counter uint64
+}
+
+func newIDGenerator(scheme IDScheme) *idGenerator {
+	g := &idGenerator{scheme: scheme}
+	if scheme == SequentialID {
+		rand.Read(g.prefix[:])
+	}
+	return g
+}
+
+// start seeds the counter of SequentialID, once Start set the clock.
+func (g *idGenerator) start() {
+	g.counter = uint64(timeNow().UnixNano())
+}
+
+func (g *idGenerator) next() string {
+	var id [16]byte
+	if g.scheme == SequentialID {
+		copy(id[:8], g.prefix[:])
+		binary.BigEndian.PutUint64(id[8:], atomic.AddUint64(&g.counter, 1))
+		return hex.EncodeToString(id[:])
+	}
+	rand.Read(id[:])
+	id[6] = id[6]&0x0f | 0x40 // version 4
+	id[8] = id[8]&0x3f | 0x80 // RFC 4122 variant
+	var b [36]byte
+	hex.Encode(b[0:8], id[0:4])
+	b[8] = '-'
+	hex.Encode(b[9:13], id[4:6])
+	b[13] = '-'
+	hex.Encode(b[14:18], id[6:8])
+	b[18] = '-'
+	hex.Encode(b[19:23], id[8:10])
+	b[23] = '-'
+	hex.Encode(b[24:], id[10:])
+	return string(b[:])
+}
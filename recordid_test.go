@@ -0,0 +1,78 @@
+package holmes
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRecordID(t *testing.T) {
+	formats := map[IDScheme]*regexp.Regexp{
+		RandomID:     regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`),
+		SequentialID: regexp.MustCompile(`^[0-9a-f]{32}$`),
+	}
+	for scheme, format := range formats {
+		ch := make(chan Record, 8000)
+		l := Start(RecordID(scheme), Channel(ch), FileLevel(FATAL))
+		wg := sync.WaitGroup{}
+		for g := 0; g < 8; g++ {
+			wg.Add(1)
+			go func() {
+				for i := 0; i < 1000; i++ {
+					Infof("%d", i)
+				}
+				wg.Done()
+			}()
+		}
+		wg.Wait()
+		l.Stop()
+		close(ch)
+
+		ids := make(map[string]bool)
+		for r := range ch {
+			id, _ := r.Fields[len(r.Fields)-1].Value.(string)
+			if !format.MatchString(id) {
+				t.Fatalf("scheme %d: got id %q", scheme, id)
+			}
+			ids[id] = true
+		}
+		if len(ids) != 8000 {
+			t.Errorf("scheme %d: got %d unique ids, want 8000", scheme, len(ids))
+		}
+	}
+}
+
+func TestSequentialIDClock(t *testing.T) {
+	clk := newTestClock(time.Date(2016, 7, 8, 11, 25, 48, 0, time.FixedZone("CST", 8*3600)))
+	ch := make(chan Record, 2)
+	l := Start(WithClock(clk), RecordID(SequentialID), Channel(ch), FileLevel(FATAL))
+	Infof("%s", "first")
+	Clone(RecordID(SequentialID)).Infof("%s", "cloned")
+	l.Stop()
+
+	for _, want := range []string{"145f333eb7295801", "145f333eb7295801"} {
+		r := <-ch
+		if id, _ := r.Fields[len(r.Fields)-1].Value.(string); !strings.HasSuffix(id, want) {
+			t.Errorf("got id %q for %q, want counter %s", id, r.Message, want)
+		}
+	}
+}
+
+func benchmarkRecordID(b *testing.B, scheme IDScheme) {
+	g := newIDGenerator(scheme)
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			g.next()
+		}
+	})
+}
+
+func BenchmarkRecordIDRandom(b *testing.B) {
+	benchmarkRecordID(b, RandomID)
+}
+
+func BenchmarkRecordIDSequential(b *testing.B) {
+	benchmarkRecordID(b, SequentialID)
+}
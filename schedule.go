@@ -0,0 +1,107 @@
+package holmes
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// ScheduleEntry sets the level of the records logged every day between Start
+// and End, given as offsets from midnight in Location, the local time zone if
+// nil. A window whose End is before its Start spans midnight.
+type ScheduleEntry struct {
+	Start    time.Duration
+	End      time.Duration
+	Level    LogLevel
+	Location *time.Location
+}
+
+// LevelSchedule returns a function to change the level of the records logged
+// as the windows of entries begin and end, e.g. DEBUG during business hours.
+// The first entry whose window includes the current time applies, the level
+// set by the other decorators applies outside all windows.
+func LevelSchedule(entries []ScheduleEntry) func(Logger) Logger {
+	return func(l Logger) Logger {
+		for _, e := range entries {
+			if e.Start < 0 || e.Start >= 24*time.Hour || e.End < 0 || e.End >= 24*time.Hour {
+				l.configErr = fmt.Errorf("holmes: LevelSchedule: window %s-%s out of a day", e.Start, e.End)
+			}
+		}
+		l.schedule = append(l.schedule, entries...)
+		return l
+	}
+}
+
+// levelScheduler switches the active level of a logger at the boundaries of
+// the windows of its schedule.
+type levelScheduler struct {
+	entries []ScheduleEntry
+	base    LogLevel
+	active  *int32
+	quit    chan struct{}
+	done    chan struct{}
+}
+
+func newLevelScheduler(entries []ScheduleEntry, base LogLevel, active *int32) *levelScheduler {
+	s := &levelScheduler{
+		entries: entries,
+		base:    base,
+		active:  active,
+		quit:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	now := timeNow()
+	level, next := s.at(now)
+	atomic.StoreInt32(s.active, int32(level))
+	go s.run(next.Sub(now))
+	return s
+}
+
+func (s *levelScheduler) run(wait time.Duration) {
+	defer close(s.done)
+	for {
+		select {
+		case <-timeAfter(wait):
+		case <-s.quit:
+			return
+		}
+		now := timeNow()
+		level, next := s.at(now)
+		atomic.StoreInt32(s.active, int32(level))
+		wait = next.Sub(now)
+	}
+}
+
+func (s *levelScheduler) stop() {
+	close(s.quit)
+	<-s.done
+}
+
+// at returns the level scheduled at now and the time of the next boundary.
+func (s *levelScheduler) at(now time.Time) (LogLevel, time.Time) {
+	level, found := s.base, false
+	var next time.Time
+	for _, e := range s.entries {
+		loc := e.Location
+		if loc == nil {
+			loc = time.Local
+		}
+		t := now.In(loc)
+		midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+		start, end := midnight.Add(e.Start), midnight.Add(e.End)
+		in := !t.Before(start) && t.Before(end)
+		if e.End < e.Start {
+			in = !t.Before(start) || t.Before(end)
+		}
+		if in && !found {
+			level, found = e.Level, true
+		}
+		tomorrow := time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		for _, b := range []time.Time{start, end, tomorrow.Add(e.Start), tomorrow.Add(e.End)} {
+			if b.After(now) && (next.IsZero() || b.Before(next)) {
+				next = b
+			}
+		}
+	}
+	return level, next
+}
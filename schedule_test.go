@@ -0,0 +1,75 @@
+package holmes
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLevelSchedule(t *testing.T) {
+	clk := newTestClock(time.Date(2016, 7, 8, 8, 59, 0, 0, time.UTC))
+	clk.waits = make(chan time.Duration)
+	advance := func(d time.Duration, wantWait time.Duration) {
+		clk.add(d)
+		if wait := <-clk.waits; wait != wantWait {
+			t.Errorf("got wait %s, want %s", wait, wantWait)
+		}
+	}
+
+	buf := &Buffer{}
+	business := ScheduleEntry{Start: 9 * time.Hour, End: 17 * time.Hour, Level: DEBUG, Location: time.UTC}
+	l := Start(WithClock(clk), InfoLevel, AlsoWriter(buf), LevelSchedule([]ScheduleEntry{business}))
+	if wait := <-clk.waits; wait != time.Minute {
+		t.Errorf("got wait %s, want 1m0s", wait)
+	}
+	Debugf("%s", "before hours")
+	advance(time.Minute, 8*time.Hour)
+	Debugf("%s", "during hours")
+	advance(8*time.Hour, 16*time.Hour)
+	Debugf("%s", "after hours")
+	Infof("%s", "always")
+	l.Stop()
+
+	got := buf.String()
+	if strings.Contains(got, "before hours") || strings.Contains(got, "after hours") {
+		t.Errorf("got debug records outside business hours: %q", got)
+	}
+	if !strings.Contains(got, "during hours") || !strings.Contains(got, "always") {
+		t.Errorf("got %q, want the records of the scheduled levels", got)
+	}
+}
+
+func TestLevelScheduleMidnight(t *testing.T) {
+	s := &levelScheduler{
+		entries: []ScheduleEntry{{Start: 22 * time.Hour, End: 6 * time.Hour, Level: ERROR, Location: time.UTC}},
+		base:    INFO,
+	}
+	tests := []struct {
+		now  time.Time
+		want LogLevel
+		next time.Time
+	}{
+		{time.Date(2016, 7, 8, 21, 0, 0, 0, time.UTC), INFO, time.Date(2016, 7, 8, 22, 0, 0, 0, time.UTC)},
+		{time.Date(2016, 7, 8, 23, 0, 0, 0, time.UTC), ERROR, time.Date(2016, 7, 9, 6, 0, 0, 0, time.UTC)},
+		{time.Date(2016, 7, 9, 1, 0, 0, 0, time.UTC), ERROR, time.Date(2016, 7, 9, 6, 0, 0, 0, time.UTC)},
+	}
+	for _, test := range tests {
+		level, next := s.at(test.now)
+		if level != test.want || !next.Equal(test.next) {
+			t.Errorf("at %s: got %s until %s, want %s until %s", test.now, level, next, test.want, test.next)
+		}
+	}
+}
+
+func TestSetLevel(t *testing.T) {
+	buf := &Buffer{}
+	l := Start(WarnLevel, AlsoWriter(buf))
+	Infof("%s", "hidden")
+	SetLevel(INFO)
+	Infof("%s", "shown")
+	l.Stop()
+
+	if got := buf.String(); strings.Contains(got, "hidden") || !strings.Contains(got, "shown") {
+		t.Errorf("got %q", got)
+	}
+}
@@ -0,0 +1,362 @@
+package holmes
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// logSegment implements io.Writer
+type logSegment struct {
+	unit         time.Duration
+	loc          *time.Location
+	logPath      string
+	logFile      *os.File
+	timeToCreate <-chan time.Time
+	maxRecords   int64
+	records      int64
+	header       []byte
+	minInterval  time.Duration
+	lastRotation time.Time
+	atomic       bool
+	final        string // name of the file being written with AtomicFinalize
+	failover     *failover
+	onRotate     func(oldPath, newPath string)
+	batch        *batcher
+	mu           *sync.Mutex // the mutex of the logger serializing the writes
+	idleClose    time.Duration
+	lastWrite    time.Time
+	idleName     string // name of the log file closed by IdleClose
+	idleQuit     chan struct{}
+	idleDone     chan struct{}
+}
+
+// IdleClose returns a function to close the log file once no record was
+// written to it for d, saving a file descriptor in mostly idle processes.
+// The file is reopened for appending on the next record.
+func IdleClose(d time.Duration) func(Logger) Logger {
+	return func(l Logger) Logger {
+		l.idleClose = d
+		return l
+	}
+}
+
+func newLogSegment(l Logger) *logSegment {
+	unit, logPath := l.unit, l.logPath
+	loc := l.location
+	if loc == nil {
+		loc = time.Local
+	}
+	now := timeNow().In(loc)
+	if logPath != "" {
+		logFile, final, err := openLogFile(l, now)
+		if err != nil && l.failoverPath == "" {
+			fmt.Fprintln(os.Stderr, err)
+			return nil
+		}
+		var header []byte
+		if l.schemaHeader {
+			header = l.fileSchemaHeader()
+			if err == nil {
+				if info, err := logFile.Stat(); err == nil && info.Size() == 0 {
+					logFile.Write(header)
+				}
+			}
+		}
+		if err != nil {
+			logFile, final = os.Stderr, "" // until the primary path is retried
+		}
+		next := nextRotation(now, unit)
+		var timeToCreate <-chan time.Time
+		if unit == 24*time.Hour || unit == time.Hour || unit == time.Minute {
+			timeToCreate = timeAfter(next.Sub(timeNow()))
+		}
+		ls := &logSegment{
+			unit:         unit,
+			loc:          loc,
+			logPath:      logPath,
+			logFile:      logFile,
+			timeToCreate: timeToCreate,
+			maxRecords:   l.maxRecords,
+			header:       header,
+			minInterval:  l.minRotation,
+			lastRotation: timeNow(),
+			atomic:       l.atomicFinalize,
+			final:        final,
+			onRotate:     l.onRotate,
+			mu:           l.mu,
+			idleClose:    l.idleClose,
+			lastWrite:    timeNow(),
+		}
+		if l.failoverPath != "" {
+			ls.failover = newFailover(l)
+			if err != nil && !ls.failover.fail(err) {
+				fmt.Fprintln(os.Stderr, err)
+				return nil
+			}
+		}
+		if l.idleClose > 0 {
+			ls.idleQuit, ls.idleDone = make(chan struct{}), make(chan struct{})
+			go ls.closeIdle()
+		}
+		if len(l.flushModes) > 0 {
+			ls.batch = newBatcher(l.flushModes, logFile)
+		}
+		return ls
+	}
+	return nil
+}
+
+// openLogFile creates the log path of l and opens the log file for now in
+// it, returning the final name of the file with AtomicFinalize.
+func openLogFile(l Logger, now time.Time) (*os.File, string, error) {
+	if err := os.MkdirAll(l.logPath, os.ModePerm); err != nil {
+		return nil, "", err
+	}
+	if l.atomicFinalize {
+		return createTempLogFile(l.logPath, now)
+	}
+	name := path.Join(l.logPath, getLogFileName(now))
+	flag := os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	if l.truncateOnStart {
+		flag = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	logFile, err := os.OpenFile(name, flag, 0666)
+	if os.IsNotExist(err) {
+		logFile, err = os.Create(name)
+	}
+	return logFile, "", err
+}
+
+func (ls *logSegment) Write(p []byte) (n int, err error) {
+	if ls.failover != nil {
+		return ls.failover.write(ls, p)
+	}
+	return ls.write(p)
+}
+
+// write writes p to the current log file, rotating it first if due.
+func (ls *logSegment) write(p []byte) (n int, err error) {
+	ls.lastWrite = timeNow()
+	if ls.idleName != "" {
+		ls.reopen()
+	}
+	if ls.logFile != os.Stdout && ls.logFile != os.Stderr {
+		if ls.timeToCreate != nil {
+			select {
+			case current := <-ls.timeToCreate:
+				ls.rotate(current.In(ls.loc))
+			default:
+				// do nothing
+			}
+		}
+		if ls.maxRecords > 0 && atomic.LoadInt64(&ls.records) >= ls.maxRecords && timeNow().Sub(ls.lastRotation) >= ls.minInterval {
+			ls.rotate(timeNow().In(ls.loc))
+		}
+	}
+	atomic.AddInt64(&ls.records, 1)
+	if ls.batch != nil {
+		ls.batch.write(p)
+		return len(p), nil
+	}
+	return ls.logFile.Write(p)
+}
+
+// WriteRecord implements RecordWriter, flushing the batched records if the
+// FlushPolicy of the level of r is Immediate.
+func (ls *logSegment) WriteRecord(r *Record, line []byte) error {
+	_, err := ls.Write(line)
+	if ls.batch != nil && ls.batch.modes[r.Level] == Immediate {
+		ls.batch.flush()
+		if ls.failover != nil && ls.failover.secondary != nil {
+			ls.failover.secondary.batch.flush()
+		}
+	}
+	return err
+}
+
+// rotate closes the current log file and creates a new one named after current.
+func (ls *logSegment) rotate(current time.Time) {
+	if ls.onRotate != nil {
+		oldPath := ls.path()
+		defer func() { go ls.onRotate(oldPath, ls.path()) }()
+	}
+	ls.closeFile()
+	ls.logFile = nil
+	atomic.StoreInt64(&ls.records, 0)
+	ls.lastRotation = timeNow()
+	var err error
+	if ls.atomic {
+		ls.logFile, ls.final, err = createTempLogFile(ls.logPath, current)
+	} else {
+		ls.logFile, err = createLogFile(ls.logPath, current)
+	}
+	if err != nil {
+		// log into stderr if we can't create new file
+		fmt.Fprintln(os.Stderr, err)
+		ls.logFile = os.Stderr
+		if ls.batch != nil {
+			ls.batch.setWriter(ls.logFile)
+		}
+		return
+	}
+	if ls.batch != nil {
+		ls.batch.setWriter(ls.logFile)
+	}
+	if ls.header != nil {
+		ls.logFile.Write(ls.header)
+	}
+	if ls.timeToCreate != nil {
+		next := nextRotation(current, ls.unit)
+		ls.timeToCreate = timeAfter(next.Sub(timeNow()))
+	}
+}
+
+// closeIdle closes the log file whenever no record was written to it for
+// ls.idleClose, until Close.
+func (ls *logSegment) closeIdle() {
+	defer close(ls.idleDone)
+	wait := ls.idleClose
+	for {
+		select {
+		case <-timeAfter(wait):
+		case <-ls.idleQuit:
+			return
+		}
+		ls.mu.Lock()
+		wait = ls.idleClose - timeNow().Sub(ls.lastWrite)
+		if wait <= 0 {
+			if ls.idleName == "" && ls.logFile != nil && ls.logFile != os.Stderr {
+				if ls.batch != nil {
+					ls.batch.flush()
+				}
+				ls.idleName = ls.logFile.Name()
+				ls.logFile.Close()
+			}
+			wait = ls.idleClose
+		}
+		ls.mu.Unlock()
+	}
+}
+
+// reopen reopens the log file closed by IdleClose for appending.
+func (ls *logSegment) reopen() {
+	logFile, err := os.OpenFile(ls.idleName, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		logFile = os.Stderr
+	}
+	ls.logFile, ls.idleName = logFile, ""
+	if ls.batch != nil {
+		ls.batch.setWriter(logFile)
+	}
+}
+
+// path returns the name of the current log file, its final name with
+// AtomicFinalize, or an empty string if there is none.
+func (ls *logSegment) path() string {
+	if ls.final != "" {
+		return ls.final
+	}
+	if ls.logFile == nil || ls.logFile == os.Stderr {
+		return ""
+	}
+	return ls.logFile.Name()
+}
+
+// Close closes the current log file, and the one of the secondary path of
+// FailoverPaths if any.
+func (ls *logSegment) Close() {
+	if ls.idleQuit != nil {
+		close(ls.idleQuit)
+		<-ls.idleDone
+	}
+	if ls.batch != nil {
+		ls.batch.stop()
+	}
+	ls.closeFile()
+	if ls.failover != nil {
+		ls.failover.close()
+	}
+}
+
+// closeFile closes the current log file, renaming it to its final name with
+// AtomicFinalize.
+func (ls *logSegment) closeFile() {
+	if ls.batch != nil {
+		ls.batch.flush()
+	}
+	if ls.logFile != os.Stderr { // left by a failed rotation, not to be closed
+		ls.logFile.Close()
+		if ls.final != "" {
+			if err := os.Rename(ls.final+".tmp", ls.final); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+		}
+	}
+	ls.idleName, ls.final = "", ""
+}
+
+// createLogFile creates a new log file for t, adding a sequence number to
+// its name if a file was already created within the same minute.
+func createLogFile(logPath string, t time.Time) (*os.File, error) {
+	for seq := 0; ; seq++ {
+		logFile, err := os.OpenFile(path.Join(logPath, sequencedLogFileName(t, seq)), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0666)
+		if !os.IsExist(err) {
+			return logFile, err
+		}
+	}
+}
+
+// createTempLogFile creates a temporary file for a log file for t, named after
+// the returned final name of the log file with a .tmp suffix.
+func createTempLogFile(logPath string, t time.Time) (*os.File, string, error) {
+	for seq := 0; ; seq++ {
+		final := path.Join(logPath, sequencedLogFileName(t, seq))
+		if _, err := os.Stat(final); !os.IsNotExist(err) {
+			continue
+		}
+		logFile, err := os.OpenFile(final+".tmp", os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0666)
+		if !os.IsExist(err) {
+			return logFile, final, err
+		}
+	}
+}
+
+// sequencedLogFileName returns the log file name for t with the sequence
+// number seq, the first one having none.
+func sequencedLogFileName(t time.Time, seq int) string {
+	if seq == 0 {
+		return getLogFileName(t)
+	}
+	return fmt.Sprintf("%s.%d.log", strings.TrimSuffix(getLogFileName(t), ".log"), seq)
+}
+
+// nextRotation returns the start of the unit following the one of t on the
+// clock of the location of t, e.g. the next full hour. Hours and minutes are
+// aligned to the offset of t, so that the hour gained or lost on a daylight
+// saving time change is a unit of its own.
+func nextRotation(t time.Time, unit time.Duration) time.Time {
+	if unit >= 24*time.Hour {
+		return time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+	}
+	_, offset := t.Zone()
+	shift := time.Duration(offset) * time.Second
+	return t.Add(shift).Truncate(unit).Add(unit).Add(-shift)
+}
+
+func getLogFileName(t time.Time) string {
+	proc := path.Base(os.Args[0])
+	year := t.Year()
+	month := t.Month()
+	day := t.Day()
+	hour := t.Hour()
+	minute := t.Minute()
+	pid := os.Getpid()
+	return fmt.Sprintf("%s.%04d-%02d-%02d-%02d-%02d.%d.log",
+		proc, year, month, day, hour, minute, pid)
+}
@@ -0,0 +1,421 @@
+package holmes
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestMaxRecords(t *testing.T) {
+	dir := t.TempDir()
+	l := Start(LogFilePath(dir), MaxRecords(50))
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			for j := 0; j < 20; j++ {
+				Infof("%d", j)
+			}
+			wg.Done()
+		}()
+	}
+	wg.Wait()
+	Infof("%s", "one more")
+	l.Stop()
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 5 {
+		t.Fatalf("got %d files, want 5", len(files))
+	}
+	total := 0
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n := strings.Count(string(data), "\n")
+		if n > 50 {
+			t.Errorf("%s has %d records, want at most 50", file, n)
+		}
+		total += n
+	}
+	if total != 201 {
+		t.Errorf("got %d records, want 201", total)
+	}
+}
+
+func TestMinRotationInterval(t *testing.T) {
+	clk := newTestClock(time.Date(2016, 7, 8, 11, 25, 0, 0, time.Local))
+
+	dir := t.TempDir()
+	l := Start(WithClock(clk), LogFilePath(dir), MaxRecords(2), MinRotationInterval(time.Minute))
+	for i := 1; i <= 30; i++ {
+		clk.add(10 * time.Second)
+		Infof("%d", i)
+	}
+	l.Stop()
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 6 {
+		t.Errorf("got %d files, want one rotation per minute", len(files))
+	}
+	if n := strings.Count(readLogs(t, dir), "\n"); n != 30 {
+		t.Errorf("got %d records, want 30", n)
+	}
+}
+
+func TestAtomicFinalize(t *testing.T) {
+	dir := t.TempDir()
+	glob := func(pattern string) int {
+		files, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(files)
+	}
+	l := Start(LogFilePath(dir), AtomicFinalize)
+	for i := 0; i < 3; i++ {
+		Infof("%d", i)
+	}
+	if logs, temps := glob("*.log"), glob("*.tmp"); logs != 0 || temps != 1 {
+		t.Errorf("got %d log files and %d temporary files before Rotate, want 0 and 1", logs, temps)
+	}
+	Rotate()
+	if n := strings.Count(readLogs(t, dir), "\n"); n != 3 {
+		t.Errorf("got %d records after Rotate, want 3", n)
+	}
+	Infof("%d", 3)
+	if logs, temps := glob("*.log"), glob("*.tmp"); logs != 1 || temps != 1 {
+		t.Errorf("got %d log files and %d temporary files after Rotate, want 1 and 1", logs, temps)
+	}
+	l.Stop()
+
+	if logs, temps := glob("*.log"), glob("*.tmp"); logs != 2 || temps != 0 {
+		t.Errorf("got %d log files and %d temporary files after Stop, want 2 and 0", logs, temps)
+	}
+	if n := strings.Count(readLogs(t, dir), "\n"); n != 4 {
+		t.Errorf("got %d records after Stop, want 4", n)
+	}
+}
+
+func TestLocation(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Kathmandu") // UTC+5:45
+	if err != nil {
+		t.Fatal(err)
+	}
+	clk := newTestClock(time.Date(2016, 7, 8, 11, 25, 0, 0, time.UTC))
+
+	dir := t.TempDir()
+	l := Start(WithClock(clk), LogFilePath(dir), Location(loc))
+	Infof("%s", "namaste")
+	l.Stop()
+
+	if content := readLogs(t, dir); !strings.HasPrefix(content, "2016/07/08 17:10:00  INFO") {
+		t.Errorf("got %q, want a timestamp in Kathmandu", content)
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("got %d files, want 1", len(files))
+	}
+	if name, want := filepath.Base(files[0]), getLogFileName(clk.Now().In(loc)); name != want {
+		t.Errorf("got file %s, want %s", name, want)
+	}
+}
+
+func TestNextRotation(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	kathmandu, err := time.LoadLocation("Asia/Kathmandu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		t    time.Time
+		unit time.Duration
+		want time.Time
+	}{
+		{"spring forward", time.Date(2016, 3, 13, 1, 30, 0, 0, newYork), time.Hour, time.Date(2016, 3, 13, 7, 0, 0, 0, time.UTC)},
+		{"fall back", time.Date(2016, 11, 6, 5, 30, 0, 0, time.UTC).In(newYork), time.Hour, time.Date(2016, 11, 6, 6, 0, 0, 0, time.UTC)},
+		{"repeated hour", time.Date(2016, 11, 6, 6, 30, 0, 0, time.UTC).In(newYork), time.Hour, time.Date(2016, 11, 6, 7, 0, 0, 0, time.UTC)},
+		{"day before spring forward", time.Date(2016, 3, 12, 12, 0, 0, 0, newYork), 24 * time.Hour, time.Date(2016, 3, 13, 5, 0, 0, 0, time.UTC)},
+		{"day after spring forward", time.Date(2016, 3, 13, 12, 0, 0, 0, newYork), 24 * time.Hour, time.Date(2016, 3, 14, 4, 0, 0, 0, time.UTC)},
+		{"quarter offset hour", time.Date(2016, 3, 13, 10, 20, 0, 0, kathmandu), time.Hour, time.Date(2016, 3, 13, 11, 0, 0, 0, kathmandu)},
+		{"quarter offset minute", time.Date(2016, 3, 13, 10, 20, 30, 0, kathmandu), time.Minute, time.Date(2016, 3, 13, 10, 21, 0, 0, kathmandu)},
+	}
+	for _, test := range tests {
+		if got := nextRotation(test.t, test.unit); !got.Equal(test.want) {
+			t.Errorf("%s: got %s, want %s", test.name, got, test.want.In(test.t.Location()))
+		}
+	}
+}
+
+func TestOnRotate(t *testing.T) {
+	dir := t.TempDir()
+	rotations := make(chan [2]string, 1)
+	l := Start(LogFilePath(dir), OnRotate(func(oldPath, newPath string) {
+		rotations <- [2]string{oldPath, newPath}
+	}))
+	Infof("%s", "old")
+	oldPath := l.segment.logFile.Name()
+	Rotate()
+	newPath := l.segment.logFile.Name()
+	Infof("%s", "new")
+	l.Stop()
+
+	paths := <-rotations
+	if paths != [2]string{oldPath, newPath} || oldPath == newPath {
+		t.Fatalf("got rotation %q, want %q", paths, [2]string{oldPath, newPath})
+	}
+	for path, want := range map[string]string{oldPath: "- old\n", newPath: "- new\n"} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasSuffix(string(data), want) {
+			t.Errorf("%s: got %q, want suffix %q", path, data, want)
+		}
+	}
+}
+
+func TestFlushPolicy(t *testing.T) {
+	clk := newTestClock(time.Date(2016, 7, 8, 11, 25, 0, 0, time.Local))
+	clk.waits = make(chan time.Duration)
+
+	dir := t.TempDir()
+	l := Start(WithClock(clk), LogFilePath(dir), FlushPolicy(map[LogLevel]FlushMode{DEBUG: Batched, INFO: Batched}))
+	<-clk.waits
+	Debugf("%s", "batched")
+	if content := readLogs(t, dir); content != "" {
+		t.Errorf("got %q before the flush interval, want nothing", content)
+	}
+	Errorf("%s", "immediate")
+	if n := strings.Count(readLogs(t, dir), "\n"); n != 2 {
+		t.Errorf("got %d records after an error, want 2", n)
+	}
+	Debugf("%s", "batched again")
+	if n := strings.Count(readLogs(t, dir), "\n"); n != 2 {
+		t.Errorf("got %d records before the flush interval, want 2", n)
+	}
+	clk.add(time.Second)
+	if wait := <-clk.waits; wait != time.Second {
+		t.Errorf("got flush interval %s, want 1s", wait)
+	}
+	if content := readLogs(t, dir); !strings.HasSuffix(content, "- batched again\n") {
+		t.Errorf("got %q after the flush interval", content)
+	}
+	l.Stop()
+}
+
+func TestAppendOnStart(t *testing.T) {
+	dir := t.TempDir()
+	l := Start(LogFilePath(dir))
+	Infof("%s", "first run")
+	l.Stop()
+	l = Start(LogFilePath(dir))
+	Infof("%s", "second run")
+	l.Stop()
+
+	content := readLogs(t, dir)
+	if !strings.Contains(content, "first run") || !strings.Contains(content, "second run") {
+		t.Errorf("got %q, want records of both runs", content)
+	}
+}
+
+func TestTruncateOnStart(t *testing.T) {
+	dir := t.TempDir()
+	l := Start(LogFilePath(dir), TruncateOnStart)
+	Infof("%s", "first run")
+	l.Stop()
+	l = Start(LogFilePath(dir), TruncateOnStart)
+	Infof("%s", "second run")
+	l.Stop()
+
+	if files, _ := filepath.Glob(filepath.Join(dir, "*.log")); len(files) > 1 {
+		t.Skip("runs logged to files of different minutes")
+	}
+	content := readLogs(t, dir)
+	if strings.Contains(content, "first run") || !strings.Contains(content, "second run") {
+		t.Errorf("got %q, want records of the second run only", content)
+	}
+}
+
+func TestSchemaHeader(t *testing.T) {
+	tests := []struct {
+		decorator func(Logger) Logger
+		header    string
+	}{
+		{JSONFormat, `{"_schema":"holmes/1","format":"json","fields":["time","level","func","file","line","msg"]}`},
+		{DebugLevel, "# holmes/1 format=text fields=time,level,func,file,line,msg"},
+	}
+	for _, test := range tests {
+		dir := t.TempDir()
+		l := Start(LogFilePath(dir), test.decorator, SchemaHeader, MaxRecords(2))
+		for i := 0; i < 5; i++ {
+			Infof("record %d", i)
+		}
+		l.Stop()
+
+		files, err := filepath.Glob(filepath.Join(dir, "*.log"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(files) != 3 {
+			t.Fatalf("got %d files, want 3", len(files))
+		}
+		records := 0
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+			if lines[0] != test.header {
+				t.Errorf("%s begins with %q, want %q", file, lines[0], test.header)
+			}
+			for _, line := range lines[1:] {
+				if !strings.Contains(line, "record ") {
+					t.Errorf("%s has unexpected line %q", file, line)
+				}
+				records++
+			}
+		}
+		if records != 5 {
+			t.Errorf("got %d records, want 5", records)
+		}
+	}
+}
+
+func TestSchemaHeaderOnAppend(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 2; i++ {
+		l := Start(LogFilePath(dir), SchemaHeader)
+		Infof("run %d", i)
+		l.Stop()
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "*.log")); len(files) > 1 {
+		t.Skip("runs logged to files of different minutes")
+	}
+	if n := strings.Count(readLogs(t, dir), "# holmes/1"); n != 1 {
+		t.Errorf("got %d headers, want 1", n)
+	}
+}
+
+func TestIdleClose(t *testing.T) {
+	clk := newTestClock(time.Date(2016, 7, 8, 11, 25, 0, 0, time.Local))
+	clk.waits = make(chan time.Duration)
+	closed := func(l Logger) bool {
+		_, err := l.segment.logFile.Stat()
+		return errors.Is(err, os.ErrClosed)
+	}
+
+	dir := t.TempDir()
+	l := Start(WithClock(clk), LogFilePath(dir), IdleClose(time.Minute))
+	<-clk.waits
+	Infof("%s", "first")
+	clk.add(30 * time.Second)
+	Infof("%s", "second")
+	clk.add(30 * time.Second)
+	if wait := <-clk.waits; wait != 30*time.Second {
+		t.Errorf("got wait %s after a record, want 30s", wait)
+	}
+	if closed(l) {
+		t.Error("log file closed 30s after a record")
+	}
+	clk.add(30 * time.Second)
+	if wait := <-clk.waits; wait != time.Minute {
+		t.Errorf("got wait %s after closing, want 1m0s", wait)
+	}
+	if !closed(l) {
+		t.Error("log file still open after a minute without records")
+	}
+	Infof("%s", "third")
+	if closed(l) {
+		t.Error("log file not reopened by a record")
+	}
+	l.Stop()
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("got %d files, want 1", len(files))
+	}
+	wants := []string{"- first", "- second", "- third"}
+	lines := strings.Split(strings.TrimSpace(readLogs(t, dir)), "\n")
+	if len(lines) != len(wants) {
+		t.Fatalf("got records %q, want %d", lines, len(wants))
+	}
+	for i, want := range wants {
+		if !strings.HasSuffix(lines[i], want) {
+			t.Errorf("got %q, want suffix %q", lines[i], want)
+		}
+	}
+}
+
+func TestEveryDay(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	clk := newTestClock(time.Date(2016, 3, 12, 23, 59, 30, 0, newYork))
+	dir := t.TempDir()
+	l := Start(WithClock(clk), LogFilePath(dir), EveryDay, Location(newYork))
+	Infof("%s", "saturday")
+	clk.add(time.Minute)
+	Infof("%s", "sunday")
+	clk.add(22*time.Hour + 59*time.Minute) // the day lasts 23 hours
+	Infof("%s", "sunday night")
+	clk.add(time.Minute)
+	Infof("%s", "monday")
+	l.Stop()
+
+	wants := []struct {
+		day     time.Time
+		records []string
+	}{
+		{time.Date(2016, 3, 12, 23, 59, 0, 0, newYork), []string{"2016/03/12 23:59:30  INFO"}},
+		{time.Date(2016, 3, 13, 0, 0, 0, 0, newYork), []string{"2016/03/13 00:00:30  INFO", "2016/03/13 23:59:30  INFO"}},
+		{time.Date(2016, 3, 14, 0, 0, 0, 0, newYork), []string{"2016/03/14 00:00:30  INFO"}},
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != len(wants) {
+		t.Fatalf("got files %v, want %d", files, len(wants))
+	}
+	for _, want := range wants {
+		name := getLogFileName(want.day)
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		if len(lines) != len(want.records) {
+			t.Fatalf("%s: got records %q, want %d", name, lines, len(want.records))
+		}
+		for i, prefix := range want.records {
+			if !strings.HasPrefix(lines[i], prefix) {
+				t.Errorf("%s: got %q, want prefix %q", name, lines[i], prefix)
+			}
+		}
+	}
+}
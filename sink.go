@@ -0,0 +1,134 @@
+package holmes
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+)
+
+// sink is an output destination of the logger with its own minimum level.
+type sink struct {
+	w         io.Writer
+	level     LogLevel
+	formatter Formatter // overrides the formatter of the logger if set
+	closer    io.Closer // closed on Stop if the sink is owned by the logger
+}
+
+// RecordWriter is implemented by writers which need the record along with
+// its formatted line, its WriteRecord is called instead of Write.
+type RecordWriter interface {
+	WriteRecord(r *Record, line []byte) error
+}
+
+// errDropped is returned by the writers of sinks dropping a record.
+var errDropped = errors.New("holmes: record dropped")
+
+func (s sink) write(r *Record, line []byte) error {
+	if rw, ok := s.w.(RecordWriter); ok {
+		return rw.WriteRecord(r, line)
+	}
+	_, err := s.w.Write(line)
+	return err
+}
+
+// AlsoWriter returns a function to make log also output to w.
+func AlsoWriter(w io.Writer) func(Logger) Logger {
+	return AlsoWriterLevel(w, DEBUG)
+}
+
+// AlsoWriterLevel returns a function to make log also output records
+// at or above level to w.
+func AlsoWriterLevel(w io.Writer, level LogLevel) func(Logger) Logger {
+	return func(l Logger) Logger {
+		l.writers = append(l.writers, sink{w: w, level: level})
+		return l
+	}
+}
+
+// AlsoWriterFormat returns a function to make log also output to w the
+// records formatted by f instead of the formatter of the logger.
+func AlsoWriterFormat(w io.Writer, f Formatter) func(Logger) Logger {
+	return func(l Logger) Logger {
+		l.writers = append(l.writers, sink{w: w, formatter: f})
+		return l
+	}
+}
+
+// FileLevel returns a function to make the log file, or stdout/stderr when
+// no log file is used, only accept records at or above level.
+func FileLevel(level LogLevel) func(Logger) Logger {
+	return func(l Logger) Logger {
+		l.fileLevel = level
+		return l
+	}
+}
+
+// StdoutLevel returns a function to make the AlsoStdout output only accept
+// records at or above level, on top of FileLevel when no log file is used.
+func StdoutLevel(level LogLevel) func(Logger) Logger {
+	return func(l Logger) Logger {
+		l.stdoutLevel = level
+		return l
+	}
+}
+
+// framedWriter writes every record as a length-prefixed frame.
+type framedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// FramedWriter returns a writer which writes each record to w as a frame
+// made of a 4-byte big-endian length followed by the record itself, so that
+// records containing newlines can be read back reliably with ReadFrame.
+func FramedWriter(w io.Writer) io.Writer {
+	return &framedWriter{w: w}
+}
+
+func (fw *framedWriter) Write(p []byte) (int, error) {
+	payload := bytes.TrimSuffix(p, []byte{'\n'})
+	frame := make([]byte, 4+len(payload))
+	binary.BigEndian.PutUint32(frame, uint32(len(payload)))
+	copy(frame[4:], payload)
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	if _, err := fw.w.Write(frame); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// ReadFrame reads a single record written by FramedWriter from r.
+func ReadFrame(r io.Reader) ([]byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	payload := make([]byte, binary.BigEndian.Uint32(header[:]))
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// Buffer is a concurrency-safe in-memory sink.
+type Buffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+// Write appends p to the buffer.
+func (b *Buffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// String returns the buffered records.
+func (b *Buffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
@@ -0,0 +1,72 @@
+package holmes
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestFramedWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := Start(AlsoWriter(FramedWriter(buf)))
+	Infof("%s", "first")
+	Warnf("%s", "second\nspans two lines")
+	Errorln("third")
+	l.Stop()
+
+	wants := []string{"first", "second\nspans two lines", "third"}
+	for _, want := range wants {
+		frame, err := ReadFrame(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasSuffix(string(frame), want) {
+			t.Errorf("got frame %q, want suffix %q", frame, want)
+		}
+	}
+	if _, err := ReadFrame(buf); err != io.EOF {
+		t.Errorf("got error %v, want io.EOF", err)
+	}
+}
+
+func TestReadFrameTruncated(t *testing.T) {
+	buf := bytes.NewBuffer([]byte{0, 0, 0, 5, 'a', 'b'})
+	if _, err := ReadFrame(buf); err != io.ErrUnexpectedEOF {
+		t.Errorf("got error %v, want io.ErrUnexpectedEOF", err)
+	}
+}
+
+func TestSinkLevels(t *testing.T) {
+	dir := t.TempDir()
+	debug := &bytes.Buffer{}
+	warn := &bytes.Buffer{}
+	l := Start(LogFilePath(dir), FileLevel(INFO), AlsoWriterLevel(debug, DEBUG), AlsoWriterLevel(warn, WARN))
+	Debugf("%s", "debug record")
+	Infof("%s", "info record")
+	Warnln("warn record")
+	Errorln("error record")
+	l.Stop()
+
+	tests := []struct {
+		name   string
+		output string
+		want   []string
+	}{
+		{"file", readLogs(t, dir), []string{"info record", "warn record", "error record"}},
+		{"debug", debug.String(), []string{"debug record", "info record", "warn record", "error record"}},
+		{"warn", warn.String(), []string{"warn record", "error record"}},
+	}
+	for _, test := range tests {
+		lines := strings.Split(strings.TrimSpace(test.output), "\n")
+		if len(lines) != len(test.want) {
+			t.Errorf("%s: got %d records, want %d", test.name, len(lines), len(test.want))
+			continue
+		}
+		for i, want := range test.want {
+			if !strings.HasSuffix(lines[i], want) {
+				t.Errorf("%s: got %q, want suffix %q", test.name, lines[i], want)
+			}
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package holmes
+
+import (
+	"fmt"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// Frame is a single stack frame of a record.
+type Frame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// StackOnError sets log output the stack trace of the calling goroutine
+// along with records at or above error level.
+func StackOnError(l Logger) Logger {
+	l.stackOnError = true
+	return l
+}
+
+// StackForErrors returns a function to set StackOnError, only capturing the
+// stack of a record logged by ErrorErr if pred reports its error is worth it,
+// e.g. func(err error) bool { return !errors.Is(err, context.Canceled) }
+func StackForErrors(pred func(err error) bool) func(Logger) Logger {
+	return func(l Logger) Logger {
+		l.stackOnError = true
+		l.stackForErrors = pred
+		return l
+	}
+}
+
+// wantsStack reports whether the stack of r is to be captured.
+func (l Logger) wantsStack(r *Record) bool {
+	if !l.stackOnError || r.Level < ERROR || r.File == "" || r.Stack != nil || r.traceOnly {
+		return false
+	}
+	if l.stackForErrors == nil {
+		return true
+	}
+	for _, f := range r.Fields {
+		if err, ok := f.Value.(error); ok && f.Key == "error" {
+			return l.stackForErrors(err)
+		}
+	}
+	return true
+}
+
+// StackFilter returns a function to omit the stack frames of functions
+// matching any of the glob patterns, e.g. "runtime.*" or "net/http.*".
+func StackFilter(patterns ...string) func(Logger) Logger {
+	filter := stackFilter(compileGlobs(patterns))
+	return func(l Logger) Logger {
+		l.stackFilter = filter
+		return l
+	}
+}
+
+// stackFilter removes frames whose function matches any of its patterns.
+type stackFilter []*regexp.Regexp
+
+func (f stackFilter) apply(frames []Frame) []Frame {
+	if len(f) == 0 {
+		return frames
+	}
+	kept := frames[:0]
+	for _, frame := range frames {
+		if !matchAny(f, frame.Function) {
+			kept = append(kept, frame)
+		}
+	}
+	return kept
+}
+
+// callerFrames returns the stack frames of the calling goroutine, skipping
+// the skip frames above the caller of callerFrames.
+func callerFrames(skip int) []Frame {
+	pcs := make([]uintptr, 32)
+	for {
+		n := runtime.Callers(2+skip, pcs) // skip runtime.Callers and callerFrames
+		if n < len(pcs) {
+			pcs = pcs[:n]
+			break
+		}
+		pcs = make([]uintptr, 2*len(pcs))
+	}
+	var stack []Frame
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		stack = append(stack, Frame{Function: frame.Function, File: frame.File, Line: frame.Line})
+		if !more {
+			break
+		}
+	}
+	return stack
+}
+
+// Recover logs the value of a panic in progress at error level along with
+// the stack of the panicking goroutine. It must be deferred directly, e.g.
+// defer holmes.Recover()
+// The panic is stopped unless RecoverRePanic is set.
+func Recover() {
+	if v := recover(); v != nil {
+		l := loggerInstance
+		l.logPanic(v, callerFrames(1)) // skip Recover
+		if l.rePanic {
+			panic(v)
+		}
+	}
+}
+
+// RecoverRePanic sets Recover resumes panicking after logging the panic.
+func RecoverRePanic(l Logger) Logger {
+	l.rePanic = true
+	return l
+}
+
+// logPanic logs v with the stack of the panicking goroutine, frames starting
+// with the frames of the runtime raising the panic.
+func (l Logger) logPanic(v interface{}, frames []Frame) {
+	sinks, traceOnly := l.sinksFor(ERROR)
+	if len(sinks) == 0 {
+		return
+	}
+	for len(frames) > 1 && strings.HasPrefix(frames[0].Function, "runtime.") {
+		frames = frames[1:]
+	}
+	if !l.fileFilter.allows(frames[0].File) {
+		return
+	}
+	l.emitTo(&Record{
+		Level:     ERROR,
+		Func:      frames[0].Function,
+		File:      frames[0].File,
+		Line:      frames[0].Line,
+		Message:   fmt.Sprintf("panic: %v", v),
+		Stack:     l.stackFilter.apply(frames),
+		traceOnly: traceOnly,
+	}, sinks)
+}
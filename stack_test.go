@@ -0,0 +1,155 @@
+package holmes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestJSONStack(t *testing.T) {
+	buf := &Buffer{}
+	l := Start(JSONFormat, StackOnError, AlsoWriter(buf))
+	Infof("%s", "no stack")
+	Errorf("%s", "with stack")
+	l.Stop()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d records, want 2", len(lines))
+	}
+	var info, record struct {
+		Line  int     `json:"line"`
+		Stack []Frame `json:"stack"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &info); err != nil {
+		t.Fatal(err)
+	}
+	if len(info.Stack) != 0 {
+		t.Errorf("got stack on info record: %v", info.Stack)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &record); err != nil {
+		t.Fatal(err)
+	}
+	if len(record.Stack) == 0 {
+		t.Fatal("missing stack on error record")
+	}
+	top := record.Stack[0]
+	if !strings.HasSuffix(top.Function, ".TestJSONStack") {
+		t.Errorf("got top function %q", top.Function)
+	}
+	if filepath.Base(top.File) != "stack_test.go" || top.Line != record.Line {
+		t.Errorf("got top frame %s:%d, want stack_test.go:%d", top.File, top.Line, record.Line)
+	}
+}
+
+func TestStackFilter(t *testing.T) {
+	buf := &Buffer{}
+	l := Start(JSONFormat, StackOnError, StackFilter("testing.*", "runtime.*"), AlsoWriter(buf))
+	Errorln("filtered")
+	l.Stop()
+
+	var record struct {
+		Stack []Frame `json:"stack"`
+	}
+	if err := json.Unmarshal([]byte(buf.String()), &record); err != nil {
+		t.Fatal(err)
+	}
+	if len(record.Stack) != 1 || !strings.HasSuffix(record.Stack[0].Function, ".TestStackFilter") {
+		t.Errorf("got stack %v, want only the test function", record.Stack)
+	}
+}
+
+func TestTextStack(t *testing.T) {
+	buf := &Buffer{}
+	l := Start(StackOnError, AlsoWriter(buf))
+	Errorf("%s", "with stack")
+	l.Stop()
+
+	if !strings.Contains(buf.String(), "with stack\n\t") || !strings.Contains(buf.String(), "stack_test.go:") {
+		t.Errorf("got %q, want stack frames after the record", buf.String())
+	}
+}
+
+func TestRecover(t *testing.T) {
+	buf := &Buffer{}
+	l := Start(AlsoWriter(buf))
+	_, _, line, _ := runtime.Caller(0)
+	func() {
+		defer Recover()
+		panic("boom")
+	}()
+	l.Stop()
+
+	want := fmt.Sprintf("ERROR [holmes.TestRecover.func1] (stack_test.go:%d) - panic: boom\n\tgithub.com/leesper/holmes.TestRecover.func1", line+3)
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+	if !strings.Contains(buf.String(), "\n\tgithub.com/leesper/holmes.TestRecover\n") {
+		t.Errorf("got %q, want the stack of the test", buf.String())
+	}
+}
+
+func TestRecoverRePanic(t *testing.T) {
+	buf := &Buffer{}
+	l := Start(AlsoWriter(buf), RecoverRePanic)
+	func() {
+		defer func() {
+			if v := recover(); v != "boom" {
+				t.Errorf("got panic %v, want boom", v)
+			}
+		}()
+		defer Recover()
+		panic("boom")
+	}()
+	l.Stop()
+
+	if !strings.Contains(buf.String(), "panic: boom") {
+		t.Errorf("got %q, want the panic logged", buf.String())
+	}
+}
+
+func TestStackForErrors(t *testing.T) {
+	buf := &Buffer{}
+	unexpected := func(err error) bool {
+		return !errors.Is(err, context.Canceled)
+	}
+	l := Start(JSONFormat, StackForErrors(unexpected), AlsoWriter(buf))
+	ErrorErr(errors.New("disk full"), "write failed")
+	ErrorErr(fmt.Errorf("request: %w", context.Canceled), "request aborted")
+	Errorf("%s", "no error value")
+	l.Stop()
+
+	wants := []struct {
+		msg, err string
+		stack    bool
+	}{
+		{"write failed", "disk full", true},
+		{"request aborted", "request: context canceled", false},
+		{"no error value", "", true},
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != len(wants) {
+		t.Fatalf("got %d lines, want %d", len(lines), len(wants))
+	}
+	for i, want := range wants {
+		var record struct {
+			Msg   string  `json:"msg"`
+			Error string  `json:"error"`
+			Stack []Frame `json:"stack"`
+		}
+		if err := json.Unmarshal([]byte(lines[i]), &record); err != nil {
+			t.Fatalf("invalid JSON %q: %v", lines[i], err)
+		}
+		if record.Msg != want.msg || record.Error != want.err {
+			t.Errorf("got msg %q error %q, want %q %q", record.Msg, record.Error, want.msg, want.err)
+		}
+		if got := len(record.Stack) > 0; got != want.stack {
+			t.Errorf("%s: got stack %v, want a stack %v", want.msg, record.Stack, want.stack)
+		}
+	}
+}
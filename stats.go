@@ -0,0 +1,125 @@
+package holmes
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// levelCounts counts the records emitted per level, ignoring the levels
+// outside DEBUG to FATAL which Log accepts too.
+type levelCounts [FATAL + 1]uint64
+
+func (c *levelCounts) inc(level LogLevel) {
+	if level < DEBUG || level > FATAL {
+		return
+	}
+	atomic.AddUint64(&c[level], 1)
+}
+
+// dec uncounts a record of level dropped after being counted, unless the
+// counts were reset since.
+func (c *levelCounts) dec(level LogLevel) {
+	if level < DEBUG || level > FATAL {
+		return
+	}
+	for {
+		n := atomic.LoadUint64(&c[level])
+		if n == 0 || atomic.CompareAndSwapUint64(&c[level], n, n-1) {
+			return
+		}
+	}
+}
+
+func (c *levelCounts) get(level LogLevel) uint64 {
+	if level < DEBUG || level > FATAL {
+		return 0
+	}
+	return atomic.LoadUint64(&c[level])
+}
+
+func (c *levelCounts) reset() {
+	for level := range c {
+		atomic.StoreUint64(&c[level], 0)
+	}
+}
+
+// LevelCounts returns the number of records logged per level since Start or
+// the last ResetLevelCounts, e.g. for an admin endpoint.
+func LevelCounts() map[LogLevel]uint64 {
+	counts := make(map[LogLevel]uint64, FATAL+1)
+	c := loggerInstance.counts
+	for level := DEBUG; level <= FATAL; level++ {
+		counts[level] = 0
+		if c != nil {
+			counts[level] = c.get(level)
+		}
+	}
+	return counts
+}
+
+// ResetLevelCounts sets the numbers of records returned by LevelCounts to zero.
+func ResetLevelCounts() {
+	if c := loggerInstance.counts; c != nil {
+		c.reset()
+	}
+}
+
+// summary returns a one-line readout of the counts, e.g.
+// "summary: debug=10 info=200 warn=3 error=1 fatal=0".
+func (c *levelCounts) summary() string {
+	var b strings.Builder
+	b.WriteString("summary:")
+	for level := DEBUG; level <= FATAL; level++ {
+		fmt.Fprintf(&b, " %s=%d", strings.ToLower(tagName[level]), c.get(level))
+	}
+	return b.String()
+}
+
+// dropStats counts the records dropped since the last drop warning.
+type dropStats struct {
+	interval time.Duration
+	pending  uint64
+	lastWarn int64 // unix nanoseconds
+}
+
+// DropWarningInterval returns a function to set how often at most a warning
+// is logged when records are being dropped, it defaults to 30 seconds.
+func DropWarningInterval(d time.Duration) func(Logger) Logger {
+	return func(l Logger) Logger {
+		l.dropInterval = d
+		return l
+	}
+}
+
+// dropped counts a record dropped by l and warns about the drops if the
+// last warning is older than the drop warning interval.
+func (l Logger) dropped() {
+	atomic.AddUint64(&l.drops.pending, 1)
+	now := timeNow().UnixNano()
+	last := atomic.LoadInt64(&l.drops.lastWarn)
+	if now-last >= int64(l.drops.interval) && atomic.CompareAndSwapInt64(&l.drops.lastWarn, last, now) {
+		l.warnDropped(Drop)
+	}
+}
+
+// warnDropped logs the number of records dropped since the last warning,
+// bypassing everything that may drop records. With the Drop policy, a warning
+// that does not fit in the Async buffer, or is evicted from it, is not counted
+// as a drop, its records are reported by the next warning instead.
+func (l Logger) warnDropped(policy OverflowPolicy) {
+	n := atomic.LoadUint64(&l.drops.pending)
+	if n == 0 {
+		return
+	}
+	queued, _ := l.send(&Record{
+		Level:   WARN,
+		Message: fmt.Sprintf("dropped %d records", n),
+		Fields:  []Field{{Key: "dropped", Value: n}},
+		drops:   n,
+	}, l.sinks, policy)
+	if queued {
+		atomic.AddUint64(&l.drops.pending, ^(n - 1))
+	}
+}
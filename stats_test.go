@@ -0,0 +1,103 @@
+package holmes
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSummaryOnStop(t *testing.T) {
+	dir := t.TempDir()
+	l := Start(LogFilePath(dir), SummaryOnStop)
+	for i := 0; i < 10; i++ {
+		Debugf("%d", i)
+	}
+	for i := 0; i < 20; i++ {
+		Infoln(i)
+	}
+	Warnf("%s", "careful")
+	Warnln("careful")
+	Warnln("careful")
+	Errorf("%s", "failed")
+	l.Stop()
+
+	content := strings.TrimSpace(readLogs(t, dir))
+	lines := strings.Split(content, "\n")
+	last := lines[len(lines)-1]
+	want := "summary: debug=10 info=20 warn=3 error=1 fatal=0"
+	if !strings.HasSuffix(last, want) {
+		t.Errorf("last line %q, want suffix %q", last, want)
+	}
+}
+
+func TestLevelCounts(t *testing.T) {
+	l := Start(LogFilePath(t.TempDir()))
+	wg := sync.WaitGroup{}
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			for i := 0; i < 100; i++ {
+				Debugf("%d", i)
+				if i%2 == 0 {
+					Infoln(i)
+				}
+				if i%10 == 0 {
+					Errorf("%d", i)
+				}
+				LevelCounts()
+			}
+			wg.Done()
+		}()
+	}
+	wg.Wait()
+	got := LevelCounts()
+	ResetLevelCounts()
+	Warnln("after reset")
+	afterReset := LevelCounts()
+	l.Stop()
+
+	want := map[LogLevel]uint64{DEBUG: 800, INFO: 400, WARN: 0, ERROR: 80, FATAL: 0}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got counts %v, want %v", got, want)
+	}
+	want = map[LogLevel]uint64{DEBUG: 0, INFO: 0, WARN: 1, ERROR: 0, FATAL: 0}
+	if !reflect.DeepEqual(afterReset, want) {
+		t.Errorf("got counts %v after reset, want %v", afterReset, want)
+	}
+}
+
+func TestDropWarning(t *testing.T) {
+	clk := newTestClock(time.Date(2016, 7, 8, 11, 25, 0, 0, time.Local))
+
+	buf := &Buffer{}
+	l := Start(WithClock(clk), AlsoWriter(buf), DropWarningInterval(30*time.Second))
+	for i := 0; i < 1000; i++ {
+		l.dropped()
+	}
+	clk.add(10 * time.Second)
+	for i := 0; i < 1000; i++ {
+		l.dropped()
+	}
+	clk.add(25 * time.Second)
+	for i := 0; i < 1000; i++ {
+		l.dropped()
+	}
+	l.Stop()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	wants := []string{
+		"WARN dropped 1 records dropped=1",
+		"WARN dropped 2000 records dropped=2000",
+		"WARN dropped 999 records dropped=999",
+	}
+	if len(lines) != len(wants) {
+		t.Fatalf("got %d warnings, want %d: %q", len(lines), len(wants), lines)
+	}
+	for i, want := range wants {
+		if !strings.HasSuffix(lines[i], want) {
+			t.Errorf("got %q, want suffix %q", lines[i], want)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package holmes
+
+import (
+	"io"
+	"log"
+	"runtime"
+	"strings"
+)
+
+// Writer returns an io.Writer logging every write as a record of level,
+// e.g. to hand holmes to code expecting an io.Writer or a *log.Logger.
+func Writer(level LogLevel) io.Writer {
+	return levelWriter(level)
+}
+
+// RedirectStdLog makes the standard library log package write through holmes
+// at level, so that the log.Printf calls of dependencies reach the holmes
+// outputs. The returned function restores the previous output and flags.
+func RedirectStdLog(level LogLevel) (restore func()) {
+	out, flags := log.Writer(), log.Flags()
+	log.SetOutput(Writer(level))
+	log.SetFlags(0)
+	return func() {
+		log.SetOutput(out)
+		log.SetFlags(flags)
+	}
+}
+
+// levelWriter logs writes with the current logger at its level.
+type levelWriter LogLevel
+
+func (w levelWriter) Write(p []byte) (int, error) {
+	loggerInstance.doWrite(LogLevel(w), string(p))
+	return len(p), nil
+}
+
+func (l Logger) doWrite(level LogLevel, msg string) {
+	sinks, traceOnly := l.sinksFor(level)
+	if len(sinks) == 0 {
+		return
+	}
+	info := writerCaller()
+	if !l.fileFilter.allows(info.file) {
+		return
+	}
+	r := &Record{Level: level, Func: info.function, File: info.file, Line: info.line, traceOnly: traceOnly}
+	render := func() {
+		r.Message = strings.TrimSuffix(msg, "\n")
+	}
+	if l.admit(r, render) {
+		l.emitTo(r, sinks)
+	}
+}
+
+// writerCaller returns the call site writing to a levelWriter, skipping the
+// frames of the log and fmt packages writing on behalf of their callers.
+func writerCaller() callerInfo {
+	var pcs [16]uintptr
+	n := runtime.Callers(4, pcs[:]) // Callers, writerCaller, doWrite and Write
+	for _, pc := range pcs[:n] {
+		info := callerAt(pc)
+		if !strings.HasPrefix(info.function, "log.") && !strings.HasPrefix(info.function, "fmt.") {
+			return info
+		}
+	}
+	return callerInfo{function: "???", file: "???"}
+}
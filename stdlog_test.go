@@ -0,0 +1,52 @@
+package holmes
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestRedirectStdLog(t *testing.T) {
+	defer log.SetOutput(log.Writer())
+	defer log.SetFlags(log.Flags())
+	previous := &bytes.Buffer{}
+	log.SetOutput(previous)
+	log.SetFlags(log.Lshortfile)
+
+	buf := &Buffer{}
+	l := Start(AlsoWriter(buf))
+	restore := RedirectStdLog(WARN)
+	_, _, line, _ := runtime.Caller(0)
+	log.Println("from", "stdlib")
+	restore()
+	log.Println("restored")
+	l.Stop()
+
+	want := fmt.Sprintf(" WARN [holmes.TestRedirectStdLog] (stdlog_test.go:%d) - from stdlib\n", line+1)
+	if !strings.HasSuffix(buf.String(), want) {
+		t.Errorf("got %q, want suffix %q", buf.String(), want)
+	}
+	if strings.Contains(buf.String(), "restored") {
+		t.Error("log output not restored")
+	}
+	if !strings.HasSuffix(previous.String(), ": restored\n") || log.Flags() != log.Lshortfile {
+		t.Errorf("got previous output %q and flags %d", previous.String(), log.Flags())
+	}
+}
+
+func TestWriter(t *testing.T) {
+	buf := &Buffer{}
+	l := Start(WarnLevel, AlsoWriter(buf))
+	_, _, line, _ := runtime.Caller(0)
+	fmt.Fprintf(Writer(ERROR), "%s failed\n", "job")
+	fmt.Fprintln(Writer(INFO), "filtered")
+	l.Stop()
+
+	want := fmt.Sprintf("ERROR [holmes.TestWriter] (stdlog_test.go:%d) - job failed\n", line+1)
+	if !strings.HasSuffix(buf.String(), want) {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
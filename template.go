@@ -0,0 +1,125 @@
+package holmes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Debugt prints debug log rendered from a message template, see Infot.
+func Debugt(template string, args map[string]interface{}) {
+	loggerInstance.doPrintf(DEBUG, "%s", templated{template, args})
+}
+
+// Infot prints info log rendered from a message template such as
+// "user {userId} logged in from {ip}", replacing each placeholder with the
+// value of the same name in args. "{{" and "}}" stand for literal braces and
+// placeholders missing from args are kept as is. The template and the values
+// are attached to the record as fields, so that records of the same template
+// can be grouped together.
+func Infot(template string, args map[string]interface{}) {
+	loggerInstance.doPrintf(INFO, "%s", templated{template, args})
+}
+
+// Warnt prints warn log rendered from a message template, see Infot.
+func Warnt(template string, args map[string]interface{}) {
+	loggerInstance.doPrintf(WARN, "%s", templated{template, args})
+}
+
+// Errort prints error log rendered from a message template, see Infot.
+func Errort(template string, args map[string]interface{}) {
+	loggerInstance.doPrintf(ERROR, "%s", templated{template, args})
+}
+
+// templated is the template and the values of a log call such as Infot,
+// expanded by splitFields only if the record is logged.
+type templated struct {
+	template string
+	args     map[string]interface{}
+}
+
+// templateArgs returns the rendered message followed by the template and
+// value fields, ready to be passed to doPrintf. Lazy values are evaluated once
+// for both the message and the fields.
+func templateArgs(template string, args map[string]interface{}) []interface{} {
+	args = resolveLazy(args)
+	msg, names := renderTemplate(template, args)
+	v := make([]interface{}, 0, len(args)+2)
+	v = append(v, msg, Field{Key: "template", Value: template})
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if value, ok := args[name]; ok && !seen[name] {
+			seen[name] = true
+			v = append(v, Field{Key: name, Value: value})
+		}
+	}
+	var extra []string
+	for name := range args {
+		if !seen[name] {
+			extra = append(extra, name)
+		}
+	}
+	sort.Strings(extra)
+	for _, name := range extra {
+		v = append(v, Field{Key: name, Value: args[name]})
+	}
+	return v
+}
+
+// resolveLazy returns args with its Lazy values evaluated, args itself if it
+// holds none.
+func resolveLazy(args map[string]interface{}) map[string]interface{} {
+	var resolved map[string]interface{}
+	for name, value := range args {
+		lazy, ok := value.(Lazy)
+		if !ok {
+			continue
+		}
+		if resolved == nil {
+			resolved = make(map[string]interface{}, len(args))
+			for name, value := range args {
+				resolved[name] = value
+			}
+		}
+		resolved[name] = lazy()
+	}
+	if resolved == nil {
+		return args
+	}
+	return resolved
+}
+
+// renderTemplate replaces the placeholders of template with args, it returns
+// the message and the placeholder names in order of appearance.
+func renderTemplate(template string, args map[string]interface{}) (string, []string) {
+	var b strings.Builder
+	var names []string
+	for i := 0; i < len(template); i++ {
+		c := template[i]
+		switch {
+		case c == '{' && strings.HasPrefix(template[i:], "{{"):
+			b.WriteByte('{')
+			i++
+		case c == '}' && strings.HasPrefix(template[i:], "}}"):
+			b.WriteByte('}')
+			i++
+		case c == '{':
+			end := strings.IndexAny(template[i+1:], "{}")
+			if end < 0 || template[i+1+end] != '}' {
+				b.WriteByte(c)
+				continue
+			}
+			name := template[i+1 : i+1+end]
+			names = append(names, name)
+			if value, ok := args[name]; ok {
+				fmt.Fprint(&b, value)
+			} else {
+				b.WriteString(template[i : i+2+end])
+			}
+			i += 1 + end
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String(), names
+}
@@ -0,0 +1,88 @@
+package holmes
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	tests := []struct {
+		template string
+		args     map[string]interface{}
+		msg      string
+		names    []string
+	}{
+		{"user {userId} logged in from {ip}", map[string]interface{}{"userId": 42, "ip": "10.0.0.1"}, "user 42 logged in from 10.0.0.1", []string{"userId", "ip"}},
+		{"no placeholders", nil, "no placeholders", nil},
+		{"user {userId} logged in from {ip}", map[string]interface{}{"userId": 42}, "user 42 logged in from {ip}", []string{"userId", "ip"}},
+		{"literal {{braces}} and {name}", map[string]interface{}{"name": "neo"}, "literal {braces} and neo", []string{"name"}},
+		{"unclosed {name", map[string]interface{}{"name": "neo"}, "unclosed {name", nil},
+		{"{a}{b}", map[string]interface{}{"a": 1, "b": 2}, "12", []string{"a", "b"}},
+	}
+	for _, test := range tests {
+		msg, names := renderTemplate(test.template, test.args)
+		if msg != test.msg || !reflect.DeepEqual(names, test.names) {
+			t.Errorf("%q: got %q %q, want %q %q", test.template, msg, names, test.msg, test.names)
+		}
+	}
+}
+
+func TestInfot(t *testing.T) {
+	buf := &Buffer{}
+	l := Start(AlsoWriter(buf))
+	Infot("user {userId} logged in from {ip}", map[string]interface{}{"ip": "10.0.0.1", "userId": 42})
+	Warnt("user {userId} logged in from {ip}", map[string]interface{}{"userId": 7, "attempt": 3})
+	l.Stop()
+
+	wants := []string{
+		`- user 42 logged in from 10.0.0.1 template="user {userId} logged in from {ip}" userId=42 ip=10.0.0.1`,
+		`- user 7 logged in from {ip} template="user {userId} logged in from {ip}" userId=7 attempt=3`,
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != len(wants) {
+		t.Fatalf("got %d lines, want %d", len(lines), len(wants))
+	}
+	for i, want := range wants {
+		if !strings.HasSuffix(lines[i], want) {
+			t.Errorf("got %q, want suffix %q", lines[i], want)
+		}
+	}
+	if !strings.Contains(lines[0], "(template_test.go:") {
+		t.Errorf("got caller %q, want template_test.go", lines[0])
+	}
+}
+
+func TestInfotLazy(t *testing.T) {
+	buf := &Buffer{}
+	calls := 0
+	answer := Lazy(func() interface{} {
+		calls++
+		return 42
+	})
+	l := Start(InfoLevel, AlsoWriter(buf))
+	Debugt("v={v}", map[string]interface{}{"v": answer})
+	if calls != 0 {
+		t.Errorf("lazy value evaluated %d times for a suppressed record", calls)
+	}
+	Infot("v={v}", map[string]interface{}{"v": answer})
+	l.Stop()
+
+	if calls != 1 {
+		t.Errorf("lazy value evaluated %d times, want 1", calls)
+	}
+	if want := `- v=42 template="v={v}" v=42` + "\n"; !strings.HasSuffix(buf.String(), want) {
+		t.Errorf("got %q, want suffix %q", buf.String(), want)
+	}
+}
+
+func BenchmarkDebugtSuppressed(b *testing.B) {
+	defer Start(InfoLevel, AlsoWriter(io.Discard)).Stop()
+	args := map[string]interface{}{"userId": 42, "ip": "10.0.0.1"}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Debugt("user {userId} logged in from {ip}", args)
+	}
+}
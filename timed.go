@@ -0,0 +1,60 @@
+package holmes
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Timed returns a function logging at info level how long passed between
+// calling Timed and calling the function, e.g.
+// defer holmes.Timed("db query")()
+func Timed(name string) func() {
+	return TimedLevel(INFO, name)
+}
+
+// TimedLevel is like Timed but logs at level.
+func TimedLevel(level LogLevel, name string) func() {
+	start := timeNow()
+	return func() {
+		elapsed := timeNow().Sub(start)
+		loggerInstance.doPrintf(level, "%s took %s", name, elapsed, Field{Key: "duration", Value: elapsed})
+	}
+}
+
+// Enter logs at debug level the entry into the function name with its args,
+// e.g. "entering Foo(1, bar)", and returns a function logging the exit with
+// its results and how long passed, e.g.
+// defer holmes.Enter("Foo", a, b)()
+// The results of a deferred call are evaluated when deferring, named results
+// are to be passed from a deferred closure instead.
+func Enter(name string, args ...interface{}) func(results ...interface{}) {
+	loggerInstance.doPrintf(DEBUG, "entering %s(%s)", name, joinArgs(args), Field{Key: "args", Value: args})
+	return leave(DEBUG, name, timeNow())
+}
+
+// EnterLevel is like Enter but logs at level.
+func EnterLevel(level LogLevel, name string, args ...interface{}) func(results ...interface{}) {
+	loggerInstance.doPrintf(level, "entering %s(%s)", name, joinArgs(args), Field{Key: "args", Value: args})
+	return leave(level, name, timeNow())
+}
+
+// leave returns the function logging the exit from the function name entered at start.
+func leave(level LogLevel, name string, start time.Time) func(results ...interface{}) {
+	return func(results ...interface{}) {
+		elapsed := timeNow().Sub(start)
+		loggerInstance.doPrintf(level, "leaving %s", name, Field{Key: "results", Value: results}, Field{Key: "duration", Value: elapsed})
+	}
+}
+
+// joinArgs returns the arguments of a call separated by commas, formatted
+// only if the record is logged.
+func joinArgs(args []interface{}) Lazy {
+	return func() interface{} {
+		s := make([]string, len(args))
+		for i, arg := range args {
+			s[i] = fmt.Sprint(arg)
+		}
+		return strings.Join(s, ", ")
+	}
+}
@@ -0,0 +1,110 @@
+package holmes
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimed(t *testing.T) {
+	buf := &Buffer{}
+	l := Start(JSONFormat, AlsoWriter(buf))
+	func() {
+		defer Timed("nap")()
+		time.Sleep(20 * time.Millisecond)
+	}()
+	l.Stop()
+
+	var record struct {
+		Level    string  `json:"level"`
+		Msg      string  `json:"msg"`
+		File     string  `json:"file"`
+		Duration float64 `json:"duration"`
+	}
+	if err := json.Unmarshal([]byte(buf.String()), &record); err != nil {
+		t.Fatalf("invalid record %q: %v", buf.String(), err)
+	}
+	if record.Level != "INFO" || !strings.HasPrefix(record.Msg, "nap took ") || record.File != "timed_test.go" {
+		t.Errorf("got record %+v", record)
+	}
+	elapsed := time.Duration(record.Duration)
+	if elapsed < 20*time.Millisecond || elapsed > time.Second {
+		t.Errorf("got duration %s, want about 20ms", elapsed)
+	}
+}
+
+func TestTimedLevel(t *testing.T) {
+	buf := &Buffer{}
+	l := Start(WarnLevel, AlsoWriter(buf))
+	TimedLevel(INFO, "suppressed")()
+	TimedLevel(WARN, "slow query")()
+	l.Stop()
+
+	if n := strings.Count(buf.String(), "\n"); n != 1 {
+		t.Fatalf("got %d records, want 1", n)
+	}
+	if !strings.Contains(buf.String(), " WARN ") || !strings.Contains(buf.String(), "- slow query took ") {
+		t.Errorf("got %q", buf.String())
+	}
+}
+
+func TestEnter(t *testing.T) {
+	buf := &Buffer{}
+	l := Start(JSONFormat, AlsoWriter(buf))
+	divide := func(a, b int) int {
+		defer Enter("divide", a, b)(a / b)
+		time.Sleep(20 * time.Millisecond)
+		return a / b
+	}
+	divide(7, 2)
+	l.Stop()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d records, want 2", len(lines))
+	}
+	var entry, exit struct {
+		Level    string        `json:"level"`
+		Msg      string        `json:"msg"`
+		File     string        `json:"file"`
+		Args     []interface{} `json:"args"`
+		Results  []interface{} `json:"results"`
+		Duration float64       `json:"duration"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("invalid record %q: %v", lines[0], err)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &exit); err != nil {
+		t.Fatalf("invalid record %q: %v", lines[1], err)
+	}
+	if entry.Level != "DEBUG" || entry.Msg != "entering divide(7, 2)" || entry.File != "timed_test.go" || len(entry.Args) != 2 || entry.Args[0] != float64(7) || entry.Args[1] != float64(2) {
+		t.Errorf("got entry record %+v", entry)
+	}
+	if exit.Level != "DEBUG" || exit.Msg != "leaving divide" || exit.File != "timed_test.go" || len(exit.Results) != 1 || exit.Results[0] != float64(3) {
+		t.Errorf("got exit record %+v", exit)
+	}
+	elapsed := time.Duration(exit.Duration)
+	if elapsed < 20*time.Millisecond || elapsed > time.Second {
+		t.Errorf("got duration %s, want about 20ms", elapsed)
+	}
+}
+
+func TestEnterLevel(t *testing.T) {
+	buf := &Buffer{}
+	l := Start(InfoLevel, AlsoWriter(buf))
+	Enter("suppressed", 1)()
+	EnterLevel(INFO, "handle", "GET", "/")("200 OK")
+	l.Stop()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d records, want 2", len(lines))
+	}
+	if !strings.HasSuffix(lines[0], `- entering handle(GET, /) args="[GET /]"`) {
+		t.Errorf("got entry record %q", lines[0])
+	}
+	if !strings.Contains(lines[1], `- leaving handle results="[200 OK]" duration=`) {
+		t.Errorf("got exit record %q", lines[1])
+	}
+}
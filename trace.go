@@ -0,0 +1,54 @@
+package holmes
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// TraceSample returns a function to also write a random sample of all records,
+// whatever their level, to log files in path, e.g. TraceSample(0.01, "trace")
+// for 1% of the traffic. The trace files rotate like the log files.
+func TraceSample(rate float64, path string) func(Logger) Logger {
+	return func(l Logger) Logger {
+		if rate < 0 || rate > 1 {
+			l.configErr = fmt.Errorf("holmes: TraceSample: rate %v out of [0, 1]", rate)
+		}
+		l.traceRate, l.tracePath = rate, path
+		return l
+	}
+}
+
+// traceSampler decides which records are written to the trace files.
+type traceSampler struct {
+	rate    float64
+	state   uint64
+	segment *logSegment
+	sinks   []sink
+}
+
+func newTraceSampler(l Logger) *traceSampler {
+	l.logPath, l.failoverPath = l.tracePath, ""
+	segment := newLogSegment(l)
+	if segment == nil {
+		return nil
+	}
+	return &traceSampler{
+		rate:    l.traceRate,
+		state:   uint64(timeNow().UnixNano()),
+		segment: segment,
+		sinks:   []sink{{w: segment, formatter: l.fileFormatter}},
+	}
+}
+
+// sample reports whether to write the next record to the trace files. It
+// draws from a splitmix64 sequence, cheap and safe for concurrent use.
+func (ts *traceSampler) sample() bool {
+	if ts.rate >= 1 {
+		return true
+	}
+	z := atomic.AddUint64(&ts.state, 0x9e3779b97f4a7c15)
+	z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+	z = (z ^ z>>27) * 0x94d049bb133111eb
+	z ^= z >> 31
+	return float64(z>>11)/(1<<53) < ts.rate
+}
@@ -0,0 +1,68 @@
+package holmes
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTraceSample(t *testing.T) {
+	tests := []struct {
+		rate     float64
+		min, max int
+	}{
+		{0, 0, 0},
+		{1, 10000, 10000},
+		{0.1, 800, 1200},
+	}
+	for _, test := range tests {
+		buf := &Buffer{}
+		dir := t.TempDir()
+		l := Start(ErrorLevel, AlsoWriter(buf), TraceSample(test.rate, dir))
+		for i := 0; i < 10000; i++ {
+			Debugf("%d", i)
+		}
+		l.Stop()
+
+		if n := strings.Count(readLogs(t, dir), "DEBUG"); n < test.min || n > test.max {
+			t.Errorf("rate %v: got %d traced records, want %d to %d", test.rate, n, test.min, test.max)
+		}
+		if buf.String() != "" {
+			t.Errorf("rate %v: traced records below the level reached the other outputs: %q", test.rate, buf.String())
+		}
+	}
+}
+
+func TestTraceSampleAlsoLogged(t *testing.T) {
+	buf := &Buffer{}
+	dir := t.TempDir()
+	l := Start(AlsoWriter(buf), TraceSample(1, dir))
+	Warnf("%s", "both")
+	l.Stop()
+
+	if !strings.Contains(buf.String(), "both") || !strings.Contains(readLogs(t, dir), "both") {
+		t.Errorf("got output %q and trace %q", buf.String(), readLogs(t, dir))
+	}
+}
+
+func TestTraceSampleOnlyTraced(t *testing.T) {
+	buf := &Buffer{}
+	dir := t.TempDir()
+	l := Start(InfoLevel, AlsoWriter(buf), TraceSample(1, dir), SummaryOnStop, RecordID(SequentialID), RateLimit(1, time.Hour), DedupByCaller(time.Hour), StackOnError)
+	for i := 0; i < 3; i++ {
+		Debugln("hidden")
+	}
+	counts := LevelCounts()
+	l.Stop()
+
+	if counts[DEBUG] != 0 {
+		t.Errorf("got %d debug records counted, want 0", counts[DEBUG])
+	}
+	trace := readLogs(t, dir)
+	if n := strings.Count(trace, "- hidden\n"); n != 3 {
+		t.Errorf("got %d traced records in %q, want 3 without fields", n, trace)
+	}
+	if !strings.Contains(buf.String(), "summary: debug=0 ") || strings.Contains(buf.String(), "dropped") {
+		t.Errorf("got output %q, want a summary without debug records nor drops", buf.String())
+	}
+}